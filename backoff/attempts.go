@@ -0,0 +1,90 @@
+package backoff
+
+import (
+	"context"
+	"iter"
+)
+
+// Attempt is a single attempt made within a loop over Attempts().
+type Attempt struct {
+	// N is the number of attempts that have already failed.
+	N uint
+
+	result *attemptResult
+}
+
+// attemptResult is the result of an attempt, as reported by Attempt.Fail().
+type attemptResult struct {
+	failed bool
+	err    error
+}
+
+// Fail marks the attempt as failed, requesting another attempt.
+//
+// err is the error that caused the failure. It is passed to the strategy to
+// compute the delay before the next attempt. If err is an error created by
+// Permanent(), or matches one of the predicates given by WithPermanentIf(), no
+// further attempts are made.
+//
+// If Fail() is called multiple times within the same attempt, the last error
+// is used.
+func (a Attempt) Fail(err error) {
+	a.result.failed = true
+	a.result.err = err
+}
+
+// Attempts returns a sequence of attempts that are delayed according to the
+// given backoff strategy.
+//
+// It is an alternative to Retry() for loops that do not fit neatly into a
+// single function, such as those that carry state between attempts. If s is
+// nil, DefaultStrategy is used.
+//
+// The body of the loop calls Attempt.Fail() to request another attempt, or
+// exits the loop using break (or return) once the attempt succeeds. An attempt
+// is considered successful if the body does not call Attempt.Fail(), in which
+// case the sequence ends.
+//
+// The sequence ends without a successful attempt if ctx is canceled, if the
+// strategy returns Stop, or if a permanent error is reported. The caller can
+// distinguish these cases using ctx.Err() and its own record of the errors
+// passed to Attempt.Fail().
+//
+// The options have the same meaning as for Retry(), except that
+// WithAttemptTimeout() has no effect, and WithRetryError() only affects the
+// error passed to Observer.OnGiveUp(). Any observers given by WithObserver()
+// are notified as per Retry(), except that OnSuccess() is not called if the
+// body exits the loop after calling Attempt.Fail().
+func Attempts(
+	ctx context.Context,
+	s Strategy,
+	options ...RetryOption,
+) iter.Seq[Attempt] {
+	return func(yield func(Attempt) bool) {
+		l := newRetryLoop(ctx, s, options)
+
+		for {
+			l.opts.observers.OnAttempt(l.n)
+
+			a := Attempt{
+				N:      l.n,
+				result: &attemptResult{},
+			}
+
+			more := yield(a)
+
+			if !a.result.failed {
+				l.succeed()
+				return
+			}
+
+			if !more {
+				return
+			}
+
+			if l.fail(ctx, a.result.err) != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/dogmatiq/linger/backoff"
+	"github.com/dogmatiq/linger/lingertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("func Attempts()", func() {
+	It("yields attempts until the body breaks", func() {
+		var indices []uint
+
+		for a := range Attempts(context.Background(), Constant(0)) {
+			indices = append(indices, a.N)
+
+			if a.N == 2 {
+				break
+			}
+
+			a.Fail(errors.New("<error>"))
+		}
+
+		Expect(indices).To(Equal([]uint{0, 1, 2}))
+	})
+
+	It("ends the sequence if the body does not call Fail()", func() {
+		count := 0
+
+		for range Attempts(context.Background(), Constant(0)) {
+			count++
+		}
+
+		Expect(count).To(Equal(1))
+	})
+
+	It("uses the default strategy if none is specified", func() {
+		count := 0
+
+		for range Attempts(context.Background(), nil) {
+			count++
+		}
+
+		Expect(count).To(Equal(1))
+	})
+
+	It("ends the sequence if the strategy returns Stop", func() {
+		var errs []error
+
+		for a := range Attempts(context.Background(), FiniteSchedule(0, 0)) {
+			err := fmt.Errorf("<error %d>", a.N)
+			errs = append(errs, err)
+			a.Fail(err)
+		}
+
+		Expect(errs).To(HaveLen(3))
+	})
+
+	It("passes the error given to Fail() to the strategy", func() {
+		var seen []error
+		strategy := func(err error, n uint) time.Duration {
+			seen = append(seen, err)
+			if n == 1 {
+				return Stop
+			}
+			return 0
+		}
+
+		for a := range Attempts(context.Background(), strategy) {
+			a.Fail(fmt.Errorf("<error %d>", a.N))
+		}
+
+		Expect(seen).To(HaveLen(2))
+		Expect(seen[0]).To(MatchError("<error 0>"))
+		Expect(seen[1]).To(MatchError("<error 1>"))
+	})
+
+	It("ends the sequence if a permanent error is reported", func() {
+		count := 0
+
+		for a := range Attempts(context.Background(), Constant(0)) {
+			count++
+			a.Fail(Permanent(errors.New("<error>")))
+		}
+
+		Expect(count).To(Equal(1))
+	})
+
+	It("ends the sequence if the body breaks after calling Fail()", func() {
+		count := 0
+
+		for a := range Attempts(context.Background(), Constant(0)) {
+			count++
+			a.Fail(errors.New("<error>"))
+			break
+		}
+
+		Expect(count).To(Equal(1))
+	})
+
+	It("sleeps between attempts according to the strategy", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		start := clock.Now()
+		result := make(chan []time.Duration, 1)
+
+		go func() {
+			var offsets []time.Duration
+
+			for a := range Attempts(
+				context.Background(),
+				Linear(10*time.Second),
+				WithClock(clock),
+			) {
+				offsets = append(offsets, clock.Now().Sub(start))
+
+				if a.N == 2 {
+					break
+				}
+
+				a.Fail(errors.New("<error>"))
+			}
+
+			result <- offsets
+		}()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(10 * time.Second)
+		clock.BlockUntilWaiters(1)
+		clock.Advance(20 * time.Second)
+
+		var offsets []time.Duration
+		Eventually(result).Should(Receive(&offsets))
+		Expect(offsets).To(Equal([]time.Duration{
+			0,
+			10 * time.Second,
+			30 * time.Second,
+		}))
+	})
+
+	It("ends the sequence if the context is canceled while sleeping", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		result := make(chan int, 1)
+
+		go func() {
+			count := 0
+
+			for a := range Attempts(ctx, Constant(time.Hour), WithClock(clock)) {
+				count++
+				a.Fail(errors.New("<error>"))
+			}
+
+			result <- count
+		}()
+
+		clock.BlockUntilWaiters(1)
+		cancel()
+
+		Eventually(result).Should(Receive(Equal(1)))
+	})
+
+	It("ends the sequence without consulting the strategy if the context is already canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		count := 0
+		strategy := func(error, uint) time.Duration {
+			Fail("unexpected call")
+			return 0
+		}
+
+		for a := range Attempts(ctx, strategy) {
+			count++
+			a.Fail(errors.New("<error>"))
+		}
+
+		Expect(count).To(Equal(1))
+	})
+
+	It("notifies observers", func() {
+		var events []string
+
+		observer := ObserverFuncs{
+			Attempt: func(n uint) {
+				events = append(events, fmt.Sprintf("attempt %d", n))
+			},
+			Failure: func(err error, n uint, d time.Duration) time.Duration {
+				events = append(events, fmt.Sprintf("failure %d", n))
+				return d
+			},
+			Success: func(n uint, _ time.Duration) {
+				events = append(events, fmt.Sprintf("success %d", n))
+			},
+			GiveUp: func(err error, n uint) {
+				events = append(events, fmt.Sprintf("give up %d %s", n, err))
+			},
+		}
+
+		for a := range Attempts(context.Background(), Constant(0), WithObserver(observer)) {
+			if a.N == 1 {
+				break
+			}
+			a.Fail(errors.New("<error>"))
+		}
+
+		for a := range Attempts(context.Background(), FiniteSchedule(), WithObserver(observer)) {
+			a.Fail(errors.New("<error>"))
+		}
+
+		Expect(events).To(Equal([]string{
+			"attempt 0",
+			"failure 0",
+			"attempt 1",
+			"success 1",
+			"attempt 0",
+			"failure 0",
+			"give up 1 backoff strategy exhausted after 1 failure(s): <error>",
+		}))
+	})
+})
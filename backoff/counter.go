@@ -2,6 +2,7 @@ package backoff
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -15,29 +16,158 @@ type Counter struct {
 	// If it is nil, DefaultStrategy is used.
 	Strategy Strategy
 
+	// Factory is used to create a new strategy for each sequence of failures.
+	// If it is non-nil it is used in preference to Strategy.
+	//
+	// A new strategy is created upon the first failure after the counter is
+	// created or reset. This allows the use of stateful strategies, such as
+	// those produced by DecorrelatedJitter().
+	Factory StrategyFactory
+
+	// Clock is used to measure the passage of time when sleeping.
+	// If it is nil, the clock carried by the context is used, as per
+	// linger.ClockFromContext().
+	Clock linger.Clock
+
+	// ElapsedLimit, if positive, is the maximum amount of time that may pass
+	// between the first failure in a sequence of failures and the next
+	// attempt.
+	//
+	// Once the delay returned by the strategy would cause the next attempt to
+	// begin later than this, Fail() returns Stop. The elapsed time is measured
+	// in the same way as the elapsed time reported to Observer.
+	ElapsedLimit time.Duration
+
+	// Observer, if non-nil, is notified of failures and successes.
+	//
+	// Its OnFailure() method is called by Fail(), and may override the delay.
+	// Its OnGiveUp() method is called with the failure's error when the delay
+	// is Stop. Its OnSuccess() method is called by Reset() if there have been
+	// any failures. OnAttempt() is never called, as the counter is not aware of
+	// attempts that succeed.
+	//
+	// The elapsed time is measured from the first failure, using Clock. If
+	// Clock is nil, the clock carried by the context passed to Sleep() is used,
+	// or linger.SystemClock if the first failure was reported by Fail() or
+	// TryFail().
+	Observer Observer
+
 	// failures is the number of successive failures that have occurred.
 	failures uint32 // atomic
+
+	// m protects changes to failures, as well as current, first and
+	// firstClock.
+	m sync.Mutex
+
+	// current is the strategy created by Factory for the current sequence of
+	// failures.
+	current Strategy
+
+	// first is the time of the first failure in the current sequence of
+	// failures, as measured by firstClock.
+	first      time.Time
+	firstClock linger.Clock
 }
 
 // Reset marks the most recent attempt as a success, resetting the counter.
 func (c *Counter) Reset() {
-	atomic.StoreUint32(&c.failures, 0)
+	c.m.Lock()
+	n := atomic.SwapUint32(&c.failures, 0)
+	c.current = nil
+	first, clock := c.first, c.firstClock
+	c.m.Unlock()
+
+	// The observer is notified without holding the lock, so that it may
+	// use the counter without deadlocking.
+	if c.Observer != nil && n > 0 {
+		c.Observer.OnSuccess(uint(n), clock.Now().Sub(first))
+	}
 }
 
 // Fail marks the most recent attempt as a failure and returns the duration to
 // wait before the operation should be retried.
 //
+// It returns Stop if the strategy indicates that no further attempts should be
+// made. Use TryFail() to check for this condition explicitly.
+//
 // err is the error describing the operation's failure condition, if known. A
 // nil error does not indicate a success.
 func (c *Counter) Fail(err error) time.Duration {
+	clock := c.Clock
+	if clock == nil {
+		clock = linger.SystemClock
+	}
+
+	return c.fail(err, clock)
+}
+
+// fail marks the most recent attempt as a failure and returns the duration to
+// wait before the operation should be retried, using clock to measure the
+// elapsed time if this is the first failure in the sequence.
+func (c *Counter) fail(err error, clock linger.Clock) time.Duration {
+	now := clock.Now()
+
+	// The failure count is incremented under the same lock that is used to
+	// record the time of the first failure, so that concurrent callers never
+	// observe a sequence of failures that has no start time.
+	c.m.Lock()
 	n := atomic.AddUint32(&c.failures, 1)
+	if n == 1 {
+		c.first, c.firstClock = now, clock
+	}
+	first, clock := c.first, c.firstClock
+	s := c.strategy()
+	c.m.Unlock()
 
-	s := c.Strategy
-	if s == nil {
-		s = DefaultStrategy
+	d := s(err, uint(n-1))
+
+	if c.ElapsedLimit > 0 {
+		d = limitElapsed(d, c.ElapsedLimit, first, clock.Now())
+	}
+
+	if c.Observer == nil {
+		return d
 	}
 
-	return s(err, uint(n-1))
+	d = c.Observer.OnFailure(err, uint(n-1), d)
+
+	if d == Stop {
+		c.Observer.OnGiveUp(err, uint(n))
+	}
+
+	return d
+}
+
+// TryFail marks the most recent attempt as a failure and returns the duration
+// to wait before the operation should be retried.
+//
+// ok is false if the strategy indicates that no further attempts should be
+// made, in which case d is meaningless.
+//
+// err is the error describing the operation's failure condition, if known. A
+// nil error does not indicate a success.
+func (c *Counter) TryFail(err error) (d time.Duration, ok bool) {
+	d = c.Fail(err)
+	return d, d != Stop
+}
+
+// strategy returns the strategy to use for the current sequence of failures.
+//
+// c.m must be locked.
+func (c *Counter) strategy() Strategy {
+	if c.Factory == nil {
+		if c.Strategy == nil {
+			return DefaultStrategy
+		}
+
+		return c.Strategy
+	}
+
+	if c.current == nil {
+		c.current = c.Factory()
+	}
+
+	return c.current
 }
 
 // Sleep marks the most recent attempt as a failure and pauses the current
@@ -47,8 +177,24 @@ func (c *Counter) Fail(err error) time.Duration {
 // If ctx is canceled before the duration elapses it returns ctx.Err(),
 // otherwise it returns nil.
 //
+// If the strategy indicates that no further attempts should be made it returns
+// an *ExhaustedError that wraps err immediately.
+//
 // err is the error describing the operation's failure condition, if known. A
 // nil error does not indicate a success.
 func (c *Counter) Sleep(ctx context.Context, err error) error {
-	return linger.Sleep(ctx, c.Fail(err))
+	clock := c.Clock
+	if clock == nil {
+		clock = linger.ClockFromContext(ctx)
+	}
+
+	d := c.fail(err, clock)
+	if d == Stop {
+		return &ExhaustedError{
+			Failures: uint(atomic.LoadUint32(&c.failures)),
+			Cause:    err,
+		}
+	}
+
+	return linger.SleepC(ctx, clock, d)
 }
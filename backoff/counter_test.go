@@ -2,9 +2,12 @@ package backoff_test
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
 	. "github.com/dogmatiq/linger/backoff"
+	"github.com/dogmatiq/linger/lingertest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -49,6 +52,78 @@ var _ = Describe("type Counter", func() {
 			Expect(counter.Fail(nil)).To(Equal(10 * time.Millisecond))
 			Expect(counter.Fail(nil)).To(Equal(20 * time.Millisecond))
 		})
+
+		It("uses a new strategy from the factory for each sequence of failures", func() {
+			created := 0
+			counter.Factory = func() Strategy {
+				created++
+				return strategy
+			}
+
+			Expect(counter.Fail(nil)).To(Equal(10 * time.Millisecond))
+			Expect(counter.Fail(nil)).To(Equal(20 * time.Millisecond))
+			Expect(created).To(Equal(1))
+
+			counter.Reset()
+
+			Expect(counter.Fail(nil)).To(Equal(10 * time.Millisecond))
+			Expect(created).To(Equal(2))
+		})
+
+		It("returns Stop once the next attempt would begin after ElapsedLimit", func() {
+			clock := lingertest.NewFakeClock(time.Now())
+			counter.Clock = clock
+			counter.Strategy = Constant(10 * time.Second)
+			counter.ElapsedLimit = 15 * time.Second
+
+			Expect(counter.Fail(nil)).To(Equal(10 * time.Second))
+
+			clock.Advance(10 * time.Second)
+			Expect(counter.Fail(nil)).To(Equal(Stop))
+
+			counter.Reset()
+			Expect(counter.Fail(nil)).To(Equal(10 * time.Second))
+		})
+
+		It("does not return Stop spuriously when called concurrently with an ElapsedLimit", func() {
+			counter.ElapsedLimit = 1 * time.Hour
+
+			var g sync.WaitGroup
+			results := make(chan time.Duration, 100)
+
+			for i := 0; i < cap(results); i++ {
+				g.Add(1)
+				go func() {
+					defer g.Done()
+					results <- counter.Fail(nil)
+				}()
+			}
+
+			g.Wait()
+			close(results)
+
+			for d := range results {
+				Expect(d).NotTo(Equal(Stop))
+			}
+		})
+	})
+
+	Describe("func TryFail()", func() {
+		It("returns the delay and true if the strategy is not exhausted", func() {
+			d, ok := counter.TryFail(nil)
+			Expect(ok).To(BeTrue())
+			Expect(d).To(Equal(10 * time.Millisecond))
+		})
+
+		It("returns false if the strategy is exhausted", func() {
+			counter.Strategy = FiniteSchedule(10 * time.Millisecond)
+
+			_, ok := counter.TryFail(nil)
+			Expect(ok).To(BeTrue())
+
+			_, ok = counter.TryFail(nil)
+			Expect(ok).To(BeFalse())
+		})
 	})
 
 	Describe("func Sleep()", func() {
@@ -61,5 +136,48 @@ var _ = Describe("type Counter", func() {
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(elapsed).To(BeNumerically(">=", 10*time.Millisecond))
 		})
+
+		It("returns an exhausted error if the strategy is exhausted", func() {
+			counter.Strategy = FiniteSchedule()
+			cause := errors.New("<error>")
+
+			err := counter.Sleep(context.Background(), cause)
+			Expect(err).To(MatchError(ErrExhausted))
+			Expect(err).To(MatchError(cause))
+		})
+
+		It("measures the ElapsedLimit using the clock carried by the context", func() {
+			clock := lingertest.NewFakeClock(time.Now())
+			ctx := clock.WithContext(context.Background())
+			counter.Strategy = Constant(10 * time.Second)
+			counter.ElapsedLimit = 15 * time.Second
+			result := make(chan error, 1)
+
+			go func() {
+				result <- counter.Sleep(ctx, nil)
+			}()
+
+			clock.BlockUntilWaiters(1)
+			clock.Advance(10 * time.Second)
+			Eventually(result).Should(Receive(BeNil()))
+
+			err := counter.Sleep(ctx, nil)
+			Expect(err).To(MatchError(ErrExhausted))
+		})
+
+		It("uses the specified clock", func() {
+			clock := lingertest.NewFakeClock(time.Now())
+			counter.Clock = clock
+			result := make(chan error, 1)
+
+			go func() {
+				result <- counter.Sleep(context.Background(), nil)
+			}()
+
+			clock.BlockUntilWaiters(1)
+			clock.Advance(10 * time.Millisecond)
+
+			Eventually(result).Should(Receive(BeNil()))
+		})
 	})
 })
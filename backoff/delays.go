@@ -0,0 +1,57 @@
+package backoff
+
+import (
+	"iter"
+	"time"
+)
+
+// Delays returns a sequence of the delays produced by s for successive
+// failures, without performing any attempts or sleeping.
+//
+// Each element of the sequence is the number of failures that have already
+// occurred (as per Strategy) and the delay that s returns for that number of
+// failures. err is passed to s as the error that caused each failure.
+//
+// The sequence ends when s returns Stop. Otherwise, it is infinite. If s is
+// nil, DefaultStrategy is used.
+//
+// It is intended for inspecting and testing strategies. To inspect a strategy
+// created by a StrategyFactory, call the factory to obtain a new strategy for
+// each sequence.
+func Delays(s Strategy, err error) iter.Seq2[uint, time.Duration] {
+	if s == nil {
+		s = DefaultStrategy
+	}
+
+	return func(yield func(uint, time.Duration) bool) {
+		for n := uint(0); ; n++ {
+			d := s(err, n)
+
+			if d == Stop || !yield(n, d) {
+				return
+			}
+		}
+	}
+}
+
+// Take returns the first n delays produced by s, as per Delays().
+//
+// It returns fewer than n delays if s returns Stop. A nil error is passed to
+// s. If s is nil, DefaultStrategy is used.
+func Take(s Strategy, n uint) []time.Duration {
+	delays := make([]time.Duration, 0, n)
+
+	if n == 0 {
+		return delays
+	}
+
+	for _, d := range Delays(s, nil) {
+		delays = append(delays, d)
+
+		if uint(len(delays)) == n {
+			break
+		}
+	}
+
+	return delays
+}
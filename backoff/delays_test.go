@@ -0,0 +1,100 @@
+package backoff_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dogmatiq/linger"
+	. "github.com/dogmatiq/linger/backoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("func Delays()", func() {
+	It("yields the delays produced by the strategy", func() {
+		var (
+			failures []uint
+			delays   []time.Duration
+		)
+
+		for n, d := range Delays(Linear(time.Second), nil) {
+			failures = append(failures, n)
+			delays = append(delays, d)
+
+			if n == 2 {
+				break
+			}
+		}
+
+		Expect(failures).To(Equal([]uint{0, 1, 2}))
+		Expect(delays).To(Equal([]time.Duration{
+			1 * time.Second,
+			2 * time.Second,
+			3 * time.Second,
+		}))
+	})
+
+	It("ends the sequence when the strategy returns Stop", func() {
+		count := 0
+
+		for range Delays(FiniteSchedule(time.Second, time.Minute), nil) {
+			count++
+		}
+
+		Expect(count).To(Equal(2))
+	})
+
+	It("passes the error to the strategy", func() {
+		cause := errors.New("<error>")
+
+		strategy := ByError(
+			Constant(time.Second),
+			OnError(cause, Constant(time.Minute)),
+		)
+
+		for _, d := range Delays(strategy, cause) {
+			Expect(d).To(Equal(time.Minute))
+			break
+		}
+	})
+
+	It("uses the default strategy if none is specified", func() {
+		for _, d := range Delays(nil, nil) {
+			Expect(d).To(BeNumerically("<=", 3*time.Second))
+			break
+		}
+	})
+})
+
+var _ = DescribeTable(
+	"func Take()",
+	func(s Strategy, n uint, expect []time.Duration) {
+		Expect(Take(s, n)).To(Equal(expect))
+	},
+	Entry(
+		"exponential",
+		Exponential(time.Second), uint(5),
+		[]time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second},
+	),
+	Entry(
+		"fibonacci",
+		Fibonacci(time.Second), uint(5),
+		[]time.Duration{1 * time.Second, 1 * time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second},
+	),
+	Entry(
+		"limited",
+		WithTransforms(Exponential(time.Second), linger.Limiter(0, 5*time.Second)), uint(4),
+		[]time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+	),
+	Entry(
+		"stops early",
+		FiniteSchedule(time.Second, time.Minute), uint(5),
+		[]time.Duration{time.Second, time.Minute},
+	),
+	Entry(
+		"none requested",
+		Constant(time.Second), uint(0),
+		[]time.Duration{},
+	),
+)
@@ -0,0 +1,129 @@
+package backoff
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExhausted indicates that a strategy has returned Stop, and hence no
+// further attempts should be made.
+//
+// Use errors.Is(err, ErrExhausted) to check if an error returned by Retry() or
+// Counter.Sleep() is caused by an exhausted strategy.
+var ErrExhausted = errors.New("backoff strategy exhausted")
+
+// ExhaustedError is the error returned by Retry() and Counter.Sleep() when the
+// strategy returns Stop.
+//
+// It wraps the error that describes the last failure, if known.
+type ExhaustedError struct {
+	// Failures is the number of successive failures that occurred before the
+	// strategy was exhausted.
+	Failures uint
+
+	// Cause is the error that describes the last failure, if known.
+	Cause error
+}
+
+func (e *ExhaustedError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("%s after %d failure(s)", ErrExhausted, e.Failures)
+	}
+
+	return fmt.Sprintf("%s after %d failure(s): %s", ErrExhausted, e.Failures, e.Cause)
+}
+
+// Is returns true if target is ErrExhausted.
+func (e *ExhaustedError) Is(target error) bool {
+	return target == ErrExhausted
+}
+
+// Unwrap returns the error that describes the last failure.
+func (e *ExhaustedError) Unwrap() error {
+	return e.Cause
+}
+
+// RetryError is the error returned by Retry() and RetryValue() when they fail
+// and the WithRetryError() option is used.
+//
+// It matches both the error that caused the retries to end and the errors
+// returned by the failed attempts, as per errors.Is() and errors.As().
+type RetryError struct {
+	// Err is the error that caused the retries to end. It is the error that
+	// would have been returned without the WithRetryError() option, such as
+	// ctx.Err() or an *ExhaustedError.
+	Err error
+
+	// Attempts is the number of times the operation was attempted.
+	Attempts uint
+
+	// Last is the error returned by the last attempt.
+	Last error
+
+	// Errors contains the errors returned by the most recent attempts, in the
+	// order they occurred. The number of errors retained is limited by the
+	// WithRetryError() option. If it is non-empty, its last element is Last.
+	Errors []error
+
+	// Waited is the total time spent waiting between attempts.
+	Waited time.Duration
+}
+
+func (e *RetryError) Error() string {
+	if e.Last == nil || e.Last == e.Err {
+		return fmt.Sprintf("failed after %d attempt(s): %s", e.Attempts, e.Err)
+	}
+
+	return fmt.Sprintf("failed after %d attempt(s): %s (last error: %s)", e.Attempts, e.Err, e.Last)
+}
+
+// Unwrap returns the error that caused the retries to end, followed by the
+// errors returned by the failed attempts.
+//
+// Last is only included separately if Errors is empty, as otherwise it is
+// already the last element of Errors.
+func (e *RetryError) Unwrap() []error {
+	errs := append([]error{e.Err}, e.Errors...)
+
+	if len(e.Errors) == 0 && e.Last != nil {
+		errs = append(errs, e.Last)
+	}
+
+	return errs
+}
+
+// Permanent returns an error that wraps err to indicate that the operation
+// should not be retried.
+//
+// When fn() returns a permanent error, Retry() returns immediately. The error
+// it returns is err, not the permanent error that wraps it. It returns nil if
+// err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err}
+}
+
+// IsPermanent returns true if err, or any error in its chain, was created by
+// Permanent().
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// permanentError is an error that indicates the operation should not be
+// retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,95 @@
+package backoff_test
+
+import (
+	"errors"
+
+	. "github.com/dogmatiq/linger/backoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("type ExhaustedError", func() {
+	It("matches ErrExhausted", func() {
+		err := &ExhaustedError{Failures: 1}
+		Expect(errors.Is(err, ErrExhausted)).To(BeTrue())
+	})
+
+	It("wraps the cause", func() {
+		cause := errors.New("<error>")
+		err := &ExhaustedError{Failures: 1, Cause: cause}
+		Expect(errors.Is(err, cause)).To(BeTrue())
+		Expect(errors.Unwrap(err)).To(Equal(cause))
+	})
+
+	Describe("func Error()", func() {
+		It("includes the cause", func() {
+			err := &ExhaustedError{Failures: 2, Cause: errors.New("<error>")}
+			Expect(err.Error()).To(Equal("backoff strategy exhausted after 2 failure(s): <error>"))
+		})
+
+		It("omits the cause if it is nil", func() {
+			err := &ExhaustedError{Failures: 2}
+			Expect(err.Error()).To(Equal("backoff strategy exhausted after 2 failure(s)"))
+		})
+	})
+})
+
+var _ = Describe("func Permanent()", func() {
+	It("returns an error that wraps the given error", func() {
+		cause := errors.New("<error>")
+		err := Permanent(cause)
+
+		Expect(err).To(MatchError("<error>"))
+		Expect(errors.Is(err, cause)).To(BeTrue())
+		Expect(IsPermanent(err)).To(BeTrue())
+	})
+
+	It("returns nil if the error is nil", func() {
+		Expect(Permanent(nil)).To(BeNil())
+	})
+})
+
+var _ = Describe("func IsPermanent()", func() {
+	It("returns false for non-permanent errors", func() {
+		Expect(IsPermanent(errors.New("<error>"))).To(BeFalse())
+		Expect(IsPermanent(nil)).To(BeFalse())
+	})
+})
+
+var _ = Describe("type RetryError", func() {
+	Describe("func Error()", func() {
+		It("omits the last error if it is the same as the cause", func() {
+			cause := errors.New("<error>")
+			err := &RetryError{Err: cause, Attempts: 1, Last: cause}
+			Expect(err.Error()).To(Equal("failed after 1 attempt(s): <error>"))
+		})
+	})
+
+	Describe("func Unwrap()", func() {
+		It("returns the cause, followed by the attempt errors", func() {
+			cause := errors.New("<cause>")
+			last := errors.New("<last>")
+			other := errors.New("<other>")
+
+			err := &RetryError{
+				Err:    cause,
+				Last:   last,
+				Errors: []error{other, last},
+			}
+
+			Expect(err.Unwrap()).To(Equal([]error{cause, other, last}))
+		})
+
+		It("includes the last error if no attempt errors are retained", func() {
+			cause := errors.New("<cause>")
+			last := errors.New("<last>")
+
+			err := &RetryError{
+				Err:  cause,
+				Last: last,
+			}
+
+			Expect(err.Unwrap()).To(Equal([]error{cause, last}))
+		})
+	})
+})
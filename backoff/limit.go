@@ -0,0 +1,48 @@
+package backoff
+
+import (
+	"time"
+)
+
+// WithMaxAttempts returns a strategy that returns Stop once the operation has
+// been attempted max times.
+//
+// Otherwise, it returns the result of s. For example, WithMaxAttempts(s, 3)
+// allows the operation to be attempted at most 3 times, that is, it allows 2
+// retries.
+//
+// It panics if max is zero.
+func WithMaxAttempts(s Strategy, max uint) Strategy {
+	if max == 0 {
+		panic("the maximum number of attempts must be positive")
+	}
+
+	return func(err error, n uint) time.Duration {
+		// n is the number of failures not including the failure indicated by
+		// err, hence n+1 attempts have already been made.
+		if n+1 >= max {
+			return Stop
+		}
+
+		return s(err, n)
+	}
+}
+
+// limitElapsed returns Stop if waiting for d would cause the next attempt to
+// begin more than max after begin, where now is the current time. Otherwise, it
+// returns d.
+func limitElapsed(d, max time.Duration, begin, now time.Time) time.Duration {
+	if d == Stop {
+		return d
+	}
+
+	// remaining is the time left before the limit is reached. It is compared to
+	// d, rather than adding d to the elapsed time, so that large delays don't
+	// overflow.
+	remaining := max - now.Sub(begin)
+	if d > remaining {
+		return Stop
+	}
+
+	return d
+}
@@ -0,0 +1,138 @@
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dogmatiq/linger"
+	. "github.com/dogmatiq/linger/backoff"
+	"github.com/dogmatiq/linger/lingertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("func WithMaxAttempts()", func() {
+	It("returns a strategy that stops after the maximum number of attempts", func() {
+		strategy := WithMaxAttempts(Linear(1*time.Second), 3)
+
+		Expect(strategy(nil, 0)).To(Equal(1 * time.Second))
+		Expect(strategy(nil, 1)).To(Equal(2 * time.Second))
+		Expect(strategy(nil, 2)).To(Equal(Stop))
+		Expect(strategy(nil, 100)).To(Equal(Stop))
+	})
+
+	It("causes Retry() to return the last error", func() {
+		cause := errors.New("<error>")
+
+		n, err := Retry(
+			context.Background(),
+			WithMaxAttempts(Constant(1*time.Nanosecond), 3),
+			func(context.Context) error {
+				return cause
+			},
+		)
+
+		Expect(err).To(MatchError(ErrExhausted))
+		Expect(err).To(MatchError(cause))
+		Expect(n).To(BeNumerically("==", 3))
+	})
+
+	It("panics if the maximum is zero", func() {
+		Expect(func() {
+			WithMaxAttempts(Constant(1*time.Second), 0)
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("func WithElapsedLimit()", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		clock  *lingertest.FakeClock
+		cause  error
+		result chan error
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		clock = lingertest.NewFakeClock(time.Now())
+		cause = errors.New("<error>")
+		result = make(chan error, 1)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	// retry runs Retry() in the background, sending its error to result.
+	retry := func(ctx context.Context, options ...RetryOption) {
+		cause, result := cause, result
+
+		go func() {
+			_, err := Retry(
+				ctx,
+				Constant(10*time.Second),
+				func(context.Context) error {
+					return cause
+				},
+				options...,
+			)
+			result <- err
+		}()
+	}
+
+	It("gives up once the next attempt would begin after the limit", func() {
+		retry(
+			ctx,
+			WithClock(clock),
+			WithElapsedLimit(25*time.Second),
+		)
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(10 * time.Second)
+		clock.BlockUntilWaiters(1)
+		clock.Advance(10 * time.Second)
+
+		var err error
+		Eventually(result).Should(Receive(&err))
+		Expect(err).To(MatchError(ErrExhausted))
+		Expect(err).To(MatchError(cause))
+		Expect(err).To(MatchError("backoff strategy exhausted after 3 failure(s): <error>"))
+	})
+
+	It("uses the clock carried by the context", func() {
+		retry(
+			linger.ContextWithClock(ctx, clock),
+			WithElapsedLimit(15*time.Second),
+		)
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(10 * time.Second)
+
+		var err error
+		Eventually(result).Should(Receive(&err))
+		Expect(err).To(MatchError("backoff strategy exhausted after 2 failure(s): <error>"))
+	})
+
+	It("does not give up while the limit has not been reached", func() {
+		retry(
+			ctx,
+			WithClock(clock),
+			WithElapsedLimit(1*time.Hour),
+		)
+
+		for i := 0; i < 5; i++ {
+			clock.BlockUntilWaiters(1)
+			clock.Advance(10 * time.Second)
+		}
+
+		Consistently(result).ShouldNot(Receive())
+	})
+
+	It("panics if the limit is not positive", func() {
+		Expect(func() {
+			WithElapsedLimit(0)
+		}).To(Panic())
+	})
+})
@@ -0,0 +1,111 @@
+package backoff
+
+import "time"
+
+// Observer is an interface for observing the progress of retries.
+//
+// It is typically used to produce metrics and logs. Observers are attached to
+// Retry() and RetryValue() using the WithObserver() option, or to a Counter
+// using its Observer field.
+type Observer interface {
+	// OnAttempt is called before each attempt is made.
+	//
+	// n is the number of attempts that have already failed.
+	OnAttempt(n uint)
+
+	// OnFailure is called when an attempt fails and the delay before the next
+	// attempt has been computed.
+	//
+	// err is the error describing the failure. n is the number of successive
+	// failures, not including this one, as per Strategy. d is the computed
+	// delay, which may be Stop.
+	//
+	// It returns the delay to use. To accept the computed delay it returns d.
+	// It may return a different duration to override the delay, or Stop to
+	// prevent any further attempts.
+	OnFailure(err error, n uint, d time.Duration) time.Duration
+
+	// OnSuccess is called when an attempt succeeds.
+	//
+	// n is the number of attempts that failed before the success. elapsed is
+	// the time since the first attempt began, or since the first failure when
+	// used with a Counter.
+	OnSuccess(n uint, elapsed time.Duration)
+
+	// OnGiveUp is called when no further attempts will be made because of a
+	// failure.
+	//
+	// err is the error that is returned to the caller, or the failure's error
+	// when used with a Counter. n is the number of attempts that failed.
+	OnGiveUp(err error, n uint)
+}
+
+// ObserverFuncs is an implementation of Observer that calls the function in
+// the corresponding field, if it is non-nil.
+type ObserverFuncs struct {
+	Attempt func(n uint)
+	Failure func(err error, n uint, d time.Duration) time.Duration
+	Success func(n uint, elapsed time.Duration)
+	GiveUp  func(err error, n uint)
+}
+
+// OnAttempt calls o.Attempt, if it is non-nil.
+func (o ObserverFuncs) OnAttempt(n uint) {
+	if o.Attempt != nil {
+		o.Attempt(n)
+	}
+}
+
+// OnFailure calls o.Failure, if it is non-nil. Otherwise, it returns d.
+func (o ObserverFuncs) OnFailure(err error, n uint, d time.Duration) time.Duration {
+	if o.Failure != nil {
+		return o.Failure(err, n, d)
+	}
+
+	return d
+}
+
+// OnSuccess calls o.Success, if it is non-nil.
+func (o ObserverFuncs) OnSuccess(n uint, elapsed time.Duration) {
+	if o.Success != nil {
+		o.Success(n, elapsed)
+	}
+}
+
+// OnGiveUp calls o.GiveUp, if it is non-nil.
+func (o ObserverFuncs) OnGiveUp(err error, n uint) {
+	if o.GiveUp != nil {
+		o.GiveUp(err, n)
+	}
+}
+
+// observers is an implementation of Observer that notifies each observer in a
+// slice, in order.
+type observers []Observer
+
+func (o observers) OnAttempt(n uint) {
+	for _, x := range o {
+		x.OnAttempt(n)
+	}
+}
+
+// OnFailure passes the delay returned by each observer to the next.
+func (o observers) OnFailure(err error, n uint, d time.Duration) time.Duration {
+	for _, x := range o {
+		d = x.OnFailure(err, n, d)
+	}
+
+	return d
+}
+
+func (o observers) OnSuccess(n uint, elapsed time.Duration) {
+	for _, x := range o {
+		x.OnSuccess(n, elapsed)
+	}
+}
+
+func (o observers) OnGiveUp(err error, n uint) {
+	for _, x := range o {
+		x.OnGiveUp(err, n)
+	}
+}
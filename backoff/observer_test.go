@@ -0,0 +1,283 @@
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/dogmatiq/linger/backoff"
+	"github.com/dogmatiq/linger/lingertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("type ObserverFuncs", func() {
+	It("calls the function in each field", func() {
+		var events []string
+
+		o := ObserverFuncs{
+			Attempt: func(n uint) {
+				events = append(events, fmt.Sprintf("attempt %d", n))
+			},
+			Failure: func(err error, n uint, d time.Duration) time.Duration {
+				events = append(events, fmt.Sprintf("failure %d %s %s", n, err, d))
+				return 2 * d
+			},
+			Success: func(n uint, elapsed time.Duration) {
+				events = append(events, fmt.Sprintf("success %d %s", n, elapsed))
+			},
+			GiveUp: func(err error, n uint) {
+				events = append(events, fmt.Sprintf("give up %d %s", n, err))
+			},
+		}
+
+		o.OnAttempt(1)
+		Expect(o.OnFailure(errors.New("<error>"), 1, time.Second)).To(Equal(2 * time.Second))
+		o.OnSuccess(2, time.Minute)
+		o.OnGiveUp(errors.New("<error>"), 3)
+
+		Expect(events).To(Equal([]string{
+			"attempt 1",
+			"failure 1 <error> 1s",
+			"success 2 1m0s",
+			"give up 3 <error>",
+		}))
+	})
+
+	It("accepts the computed delay if the Failure field is nil", func() {
+		var o ObserverFuncs
+
+		Expect(func() {
+			o.OnAttempt(1)
+			o.OnSuccess(1, time.Second)
+			o.OnGiveUp(errors.New("<error>"), 1)
+		}).NotTo(Panic())
+
+		Expect(o.OnFailure(errors.New("<error>"), 1, time.Second)).To(Equal(time.Second))
+	})
+})
+
+var _ = Describe("func Retry() (with the WithObserver() option)", func() {
+	It("notifies the observer of each attempt, failure and the eventual success", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		var events []string
+		count := 0
+
+		n, err := Retry(
+			context.Background(),
+			Constant(0),
+			func(context.Context) error {
+				clock.Advance(time.Second)
+
+				if count == 2 {
+					return nil
+				}
+
+				count++
+				return fmt.Errorf("<error %d>", count)
+			},
+			WithClock(clock),
+			WithObserver(ObserverFuncs{
+				Attempt: func(n uint) {
+					events = append(events, fmt.Sprintf("attempt %d", n))
+				},
+				Failure: func(err error, n uint, d time.Duration) time.Duration {
+					events = append(events, fmt.Sprintf("failure %d %s", n, err))
+					return d
+				},
+				Success: func(n uint, elapsed time.Duration) {
+					events = append(events, fmt.Sprintf("success %d %s", n, elapsed))
+				},
+				GiveUp: func(err error, n uint) {
+					events = append(events, "give up")
+				},
+			}),
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(BeNumerically("==", 2))
+		Expect(events).To(Equal([]string{
+			"attempt 0",
+			"failure 0 <error 1>",
+			"attempt 1",
+			"failure 1 <error 2>",
+			"attempt 2",
+			"success 2 3s",
+		}))
+	})
+
+	It("notifies the observer when giving up", func() {
+		var (
+			giveUpErr error
+			giveUpN   uint
+		)
+
+		_, err := Retry(
+			context.Background(),
+			FiniteSchedule(0),
+			func(context.Context) error {
+				return errors.New("<error>")
+			},
+			WithObserver(ObserverFuncs{
+				GiveUp: func(err error, n uint) {
+					giveUpErr = err
+					giveUpN = n
+				},
+			}),
+		)
+
+		Expect(err).To(MatchError(ErrExhausted))
+		Expect(giveUpErr).To(Equal(err))
+		Expect(giveUpN).To(BeNumerically("==", 2))
+	})
+
+	It("uses the delay returned by the observer", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		result := make(chan error, 1)
+		computed := make(chan time.Duration, 2)
+
+		go func() {
+			_, err := Retry(
+				context.Background(),
+				Constant(time.Hour),
+				func(context.Context) error {
+					return errors.New("<error>")
+				},
+				WithClock(clock),
+				WithObserver(ObserverFuncs{
+					Failure: func(err error, n uint, d time.Duration) time.Duration {
+						computed <- d
+						if n == 1 {
+							return Stop
+						}
+						return time.Second
+					},
+				}),
+			)
+			result <- err
+		}()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(time.Second)
+
+		var err error
+		Eventually(result).Should(Receive(&err))
+
+		var ee *ExhaustedError
+		Expect(errors.As(err, &ee)).To(BeTrue())
+		Expect(ee.Failures).To(BeNumerically("==", 2))
+		Expect(computed).To(Receive(Equal(time.Hour)))
+		Expect(computed).To(Receive(Equal(time.Hour)))
+	})
+
+	It("chains the delay through multiple observers in order", func() {
+		var seen []time.Duration
+
+		_, err := Retry(
+			context.Background(),
+			Constant(time.Hour),
+			func(context.Context) error {
+				return errors.New("<error>")
+			},
+			WithObserver(ObserverFuncs{
+				Failure: func(err error, n uint, d time.Duration) time.Duration {
+					seen = append(seen, d)
+					return time.Minute
+				},
+			}),
+			WithObserver(ObserverFuncs{
+				Failure: func(err error, n uint, d time.Duration) time.Duration {
+					seen = append(seen, d)
+					return Stop
+				},
+			}),
+		)
+
+		Expect(err).To(MatchError(ErrExhausted))
+		Expect(seen).To(Equal([]time.Duration{time.Hour, time.Minute}))
+	})
+})
+
+var _ = Describe("type Counter (with an observer)", func() {
+	var (
+		clock   *lingertest.FakeClock
+		events  []string
+		counter *Counter
+	)
+
+	BeforeEach(func() {
+		clock = lingertest.NewFakeClock(time.Now())
+		events = nil
+
+		counter = &Counter{
+			Strategy: FiniteSchedule(time.Second, 2*time.Second),
+			Clock:    clock,
+			Observer: ObserverFuncs{
+				Failure: func(err error, n uint, d time.Duration) time.Duration {
+					events = append(events, fmt.Sprintf("failure %d %s %s", n, err, d))
+					return d
+				},
+				Success: func(n uint, elapsed time.Duration) {
+					events = append(events, fmt.Sprintf("success %d %s", n, elapsed))
+				},
+				GiveUp: func(err error, n uint) {
+					events = append(events, fmt.Sprintf("give up %d %s", n, err))
+				},
+			},
+		}
+	})
+
+	It("notifies the observer of failures and the eventual success", func() {
+		counter.Fail(errors.New("<error>"))
+		clock.Advance(5 * time.Second)
+		counter.Fail(errors.New("<error>"))
+		counter.Reset()
+
+		Expect(events).To(Equal([]string{
+			"failure 0 <error> 1s",
+			"failure 1 <error> 2s",
+			"success 2 5s",
+		}))
+	})
+
+	It("does not notify the observer of a success if there were no failures", func() {
+		counter.Reset()
+		Expect(events).To(BeEmpty())
+	})
+
+	It("notifies the observer when the strategy is exhausted", func() {
+		counter.Fail(errors.New("<error>"))
+		counter.Fail(errors.New("<error>"))
+		d := counter.Fail(errors.New("<error>"))
+
+		Expect(d).To(Equal(Stop))
+		Expect(events).To(ContainElement("give up 3 <error>"))
+	})
+
+	It("uses the delay returned by the observer", func() {
+		counter.Observer = ObserverFuncs{
+			Failure: func(err error, n uint, d time.Duration) time.Duration {
+				return 10 * d
+			},
+		}
+
+		Expect(counter.Fail(nil)).To(Equal(10 * time.Second))
+	})
+
+	It("allows the observer to use the counter when notified of a success", func() {
+		counter.Observer = ObserverFuncs{
+			Success: func(uint, time.Duration) {
+				counter.Reset()
+				events = append(events, fmt.Sprintf("delay %s", counter.Fail(nil)))
+			},
+		}
+
+		counter.Fail(nil)
+		counter.Reset()
+
+		Expect(events).To(Equal([]string{
+			"delay 1s",
+		}))
+	})
+})
@@ -2,6 +2,8 @@ package backoff
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/dogmatiq/linger"
 )
@@ -11,28 +13,454 @@ import (
 // Each subsequent call is delayed according to the given backoff strategy.
 // If s is nil, DefaultStrategy is used.
 //
-// It returns ctx.Err() if ctx is canceled before fn() succeeds.
+// It returns ctx.Err() if ctx is canceled before fn() succeeds. If the strategy
+// returns Stop, it returns an *ExhaustedError that wraps the error from the
+// last call to fn().
+//
+// If fn() returns an error created by Permanent(), it returns the error that
+// was passed to Permanent() immediately. Likewise, if fn() returns an error
+// that matches one of the predicates given by WithPermanentIf(), it returns
+// that error immediately.
+//
 // n is the number of times that fn() failed, even if err is non-nil.
 func Retry(
 	ctx context.Context,
 	s Strategy,
 	fn func(ctx context.Context) error,
+	options ...RetryOption,
 ) (n uint, err error) {
+	_, n, err = RetryValue(
+		ctx,
+		s,
+		func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, fn(ctx)
+		},
+		options...,
+	)
+
+	return n, err
+}
+
+// RetryValue calls the given function until it succeeds, and returns the value
+// it produces.
+//
+// It behaves like Retry(), except that fn() returns a value along with its
+// error. The value returned by the successful call is returned as v. If fn()
+// never succeeds, v is the zero-value of T.
+func RetryValue[T any](
+	ctx context.Context,
+	s Strategy,
+	fn func(ctx context.Context) (T, error),
+	options ...RetryOption,
+) (v T, n uint, err error) {
+	l := newRetryLoop(ctx, s, options)
+
+	for {
+		l.opts.observers.OnAttempt(l.n)
+
+		v, err := attempt(ctx, l.clock, &l.opts, fn, l.hist.last, l.n)
+		if err == nil {
+			l.succeed()
+			return v, l.n, nil
+		}
+
+		if err := l.fail(ctx, err); err != nil {
+			var zero T
+			return zero, l.n, err
+		}
+	}
+}
+
+// retryLoop is the state of a loop that makes attempts until one succeeds. It
+// is shared by RetryValue() and Attempts().
+type retryLoop struct {
+	s      Strategy
+	opts   retryOptions
+	clock  linger.Clock
+	begin  time.Time
+	n      uint
+	hist   retryHistory
+	fitted bool
+}
+
+// newRetryLoop returns a new retry loop that uses the strategy s, configured
+// by the given options.
+func newRetryLoop(
+	ctx context.Context,
+	s Strategy,
+	options []RetryOption,
+) *retryLoop {
 	if s == nil {
 		s = DefaultStrategy
 	}
 
-	for {
-		err := fn(ctx)
-		if err == nil {
-			return n, nil
+	opts := newRetryOptions(options)
+	clock := opts.clock
+	if clock == nil {
+		clock = linger.ClockFromContext(ctx)
+	}
+
+	return &retryLoop{
+		s:     s,
+		opts:  opts,
+		clock: clock,
+		begin: clock.Now(),
+	}
+}
+
+// succeed records the success of the current attempt.
+func (l *retryLoop) succeed() {
+	l.opts.observers.OnSuccess(l.n, l.clock.Now().Sub(l.begin))
+}
+
+// fail records the failure of the current attempt and sleeps until the next
+// attempt should be made.
+//
+// It returns a non-nil error if no further attempts should be made.
+func (l *retryLoop) fail(ctx context.Context, err error) error {
+	l.hist.record(err, l.opts.history)
+
+	if p, ok := l.opts.permanent(err); ok {
+		l.n++
+
+		// Permanent errors are returned as-is, even if the WithRetryError()
+		// option is used.
+		l.opts.observers.OnGiveUp(p, l.n)
+		return p
+	}
+
+	// If the attempt failed because ctx itself was canceled, as opposed to the
+	// per-attempt timeout, there's no point in retrying.
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		l.n++
+		return l.giveUp(ctxErr)
+	}
+
+	d := l.s(err, l.n)
+	l.n++
+
+	if l.opts.elapsedLimit > 0 {
+		d = limitElapsed(d, l.opts.elapsedLimit, l.begin, l.clock.Now())
+	}
+
+	if d != Stop && l.opts.deadline != ignoreDeadline {
+		d = l.opts.fitToDeadline(ctx, l.clock, d, &l.fitted)
+	}
+
+	d = l.opts.observers.OnFailure(err, l.n-1, d)
+
+	if d == Stop {
+		return l.giveUp(&ExhaustedError{
+			Failures: l.n,
+			Cause:    err,
+		})
+	}
+
+	start := l.clock.Now()
+	err = linger.SleepC(ctx, l.clock, d)
+	l.hist.waited += l.clock.Now().Sub(start)
+
+	if err != nil {
+		return l.giveUp(err)
+	}
+
+	return nil
+}
+
+// giveUp returns the error to report when no further attempts are made
+// because of err.
+func (l *retryLoop) giveUp(err error) error {
+	if l.opts.retryError {
+		err = l.hist.newError(l.n, err)
+	}
+
+	l.opts.observers.OnGiveUp(err, l.n)
+
+	return err
+}
+
+// attempt calls fn(), applying the per-attempt timeout, if any.
+//
+// prev is the error from the previous attempt, if any. n is the number of
+// attempts that have already failed.
+func attempt[T any](
+	ctx context.Context,
+	clock linger.Clock,
+	opts *retryOptions,
+	fn func(ctx context.Context) (T, error),
+	prev error,
+	n uint,
+) (T, error) {
+	if opts.attemptTimeout != nil {
+		if d := opts.attemptTimeout(prev, n); d > 0 {
+			var cancel func()
+			ctx, cancel = linger.ContextWithTimeoutC(ctx, clock, d)
+			defer cancel()
 		}
+	}
+
+	return fn(ctx)
+}
+
+// RetryOption is an option that changes the behavior of Retry(), RetryValue()
+// and Attempts().
+type RetryOption func(*retryOptions)
+
+// WithClock returns a RetryOption that causes Retry() and RetryValue() to use c
+// to measure the delay between attempts.
+//
+// By default, the clock carried by the context is used, as per
+// linger.ClockFromContext().
+func WithClock(c linger.Clock) RetryOption {
+	return func(opts *retryOptions) {
+		opts.clock = c
+	}
+}
+
+// WithPermanentIf returns a RetryOption that causes Retry() and RetryValue() to
+// return immediately if isPermanent(err) returns true for an error returned by
+// fn().
+//
+// It allows errors to be classified as non-retryable, typically using
+// errors.Is() or errors.As(), without wrapping them with Permanent(). This
+// option may be given multiple times, in which case an error is permanent if
+// any of the predicates returns true.
+func WithPermanentIf(isPermanent func(err error) bool) RetryOption {
+	return func(opts *retryOptions) {
+		opts.isPermanent = append(opts.isPermanent, isPermanent)
+	}
+}
 
-		d := s(err, n)
-		n++
+// WithRetryError returns a RetryOption that causes Retry() and RetryValue() to
+// return a *RetryError when they fail.
+//
+// The *RetryError describes the failed attempts, including the error from the
+// last attempt, which is otherwise lost if ctx is canceled. history is the
+// maximum number of attempt errors to retain in RetryError.Errors. The most
+// recent errors are retained. If history is zero, only the last error is
+// retained.
+//
+// Permanent errors, as per Permanent() and WithPermanentIf(), are still
+// returned immediately without being wrapped in a *RetryError.
+func WithRetryError(history uint) RetryOption {
+	return func(opts *retryOptions) {
+		opts.retryError = true
+		opts.history = history
+	}
+}
+
+// WithAttemptTimeout returns a RetryOption that causes Retry() and
+// RetryValue() to apply a timeout to each attempt.
+//
+// The timeout is computed by calling t with the error from the previous
+// attempt, which is nil for the first attempt, and the number of attempts that
+// have already failed. This allows later attempts to be given longer timeouts,
+// for example by passing Linear(5*time.Second). The timeout can be jittered
+// using WithTransforms(). If t returns a non-positive duration, the attempt
+// is not subject to a timeout.
+//
+// An attempt that fails because its timeout is reached is retried like any
+// other failure. If ctx itself is canceled, no further attempts are made.
+func WithAttemptTimeout(t Strategy) RetryOption {
+	return func(opts *retryOptions) {
+		opts.attemptTimeout = t
+	}
+}
+
+// WithElapsedLimit returns a RetryOption that causes Retry() and RetryValue()
+// to give up if the next attempt would begin more than limit after the first
+// attempt began.
+//
+// Time is measured using the same clock as the delay between attempts, as
+// per WithClock(). Once the limit is reached, an *ExhaustedError that wraps the
+// error from the last attempt is returned. It panics if limit is not positive.
+func WithElapsedLimit(limit time.Duration) RetryOption {
+	if limit <= 0 {
+		panic("the elapsed time limit must be positive")
+	}
+
+	return func(opts *retryOptions) {
+		opts.elapsedLimit = limit
+	}
+}
+
+// WithStopBeforeDeadline returns a RetryOption that causes Retry() and
+// RetryValue() to give up if the next attempt would not begin at least reserve
+// before the deadline of ctx.
+//
+// Without this option, a delay that outlasts the deadline causes ctx.Err() to
+// be returned once the deadline is reached, and the error from the last attempt
+// is lost. With this option, an *ExhaustedError that wraps the error from the
+// last attempt is returned immediately instead. reserve is the amount of time
+// needed to perform an attempt, it may be zero.
+//
+// It has no effect if ctx does not have a deadline.
+func WithStopBeforeDeadline(reserve time.Duration) RetryOption {
+	if reserve < 0 {
+		panic("the reserve duration must not be negative")
+	}
+
+	return func(opts *retryOptions) {
+		opts.deadline = stopBeforeDeadline
+		opts.reserve = reserve
+	}
+}
+
+// WithFitToDeadline returns a RetryOption that causes Retry() and RetryValue()
+// to shorten the final delay so that one last attempt begins reserve before
+// the deadline of ctx.
+//
+// If the delay produced by the strategy would cause the next attempt to begin
+// less than reserve before the deadline, it is shortened so that the attempt
+// begins exactly reserve before the deadline. If that attempt also fails, or
+// there is not even reserve remaining before the deadline, an *ExhaustedError
+// that wraps the error from the last attempt is returned. reserve is the
+// amount of time needed to perform an attempt, it must be positive.
+//
+// It has no effect if ctx does not have a deadline.
+func WithFitToDeadline(reserve time.Duration) RetryOption {
+	if reserve <= 0 {
+		panic("the reserve duration must be positive")
+	}
+
+	return func(opts *retryOptions) {
+		opts.deadline = fitToDeadline
+		opts.reserve = reserve
+	}
+}
+
+// WithObserver returns a RetryOption that causes Retry() and RetryValue() to
+// notify o of their progress.
+//
+// This option may be given multiple times, in which case the observers are
+// notified in the order they are given. Each observer's OnFailure() method is
+// passed the delay returned by the previous observer.
+func WithObserver(o Observer) RetryOption {
+	return func(opts *retryOptions) {
+		opts.observers = append(opts.observers, o)
+	}
+}
+
+// deadlineMode is an enumeration of the ways in which Retry() and
+// RetryValue() account for the deadline of the context.
+type deadlineMode int
+
+const (
+	ignoreDeadline deadlineMode = iota
+	stopBeforeDeadline
+	fitToDeadline
+)
+
+// retryOptions is the set of options that affect the behavior of Retry() and
+// RetryValue().
+type retryOptions struct {
+	clock          linger.Clock
+	isPermanent    []func(error) bool
+	retryError     bool
+	history        uint
+	attemptTimeout Strategy
+	elapsedLimit   time.Duration
+	deadline       deadlineMode
+	reserve        time.Duration
+	observers      observers
+}
 
-		if err := linger.Sleep(ctx, d); err != nil {
-			return n, err
+// permanent returns the error that should be returned if err indicates
+// that the operation should not be retried.
+//
+// ok is false if the operation should be retried.
+func (o *retryOptions) permanent(err error) (_ error, ok bool) {
+	var p *permanentError
+	if errors.As(err, &p) {
+		return p.err, true
+	}
+
+	for _, fn := range o.isPermanent {
+		if fn(err) {
+			return err, true
 		}
 	}
+
+	return nil, false
+}
+
+// newRetryOptions returns the retryOptions produced by applying the given
+// options to the default options.
+func newRetryOptions(options []RetryOption) retryOptions {
+	var opts retryOptions
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	return opts
+}
+
+// fitToDeadline returns the delay to use in place of d such that the next
+// attempt begins at least o.reserve before the deadline of ctx.
+//
+// It returns Stop if no further attempts should be made. fitted is set to true
+// when the delay is shortened, and prevents it from being shortened again.
+func (o *retryOptions) fitToDeadline(
+	ctx context.Context,
+	clock linger.Clock,
+	d time.Duration,
+	fitted *bool,
+) time.Duration {
+	remaining, ok := linger.FromContextDeadlineC(ctx, clock)
+	if !ok {
+		return d
+	}
+
+	// available is the longest delay that still allows the next attempt to
+	// begin at least o.reserve before the deadline.
+	available := remaining - o.reserve
+
+	if d < available {
+		return d
+	}
+
+	if o.deadline == fitToDeadline && available >= 0 && !*fitted {
+		*fitted = true
+		return available
+	}
+
+	return Stop
+}
+
+// retryHistory records information about failed attempts for use in a
+// *RetryError.
+type retryHistory struct {
+	last   error
+	errors []error
+	waited time.Duration
+}
+
+// record records err as the error from the most recent attempt, retaining at
+// most limit errors in total.
+func (h *retryHistory) record(err error, limit uint) {
+	h.last = err
+
+	if limit == 0 {
+		return
+	}
+
+	if uint(len(h.errors)) == limit {
+		copy(h.errors, h.errors[1:])
+		h.errors = h.errors[:limit-1]
+	}
+
+	h.errors = append(h.errors, err)
+}
+
+// newError returns a *RetryError that describes a retry loop that failed with
+// the given error after n attempts.
+func (h *retryHistory) newError(n uint, err error) *RetryError {
+	return &RetryError{
+		Err:      err,
+		Attempts: n,
+		Last:     h.last,
+		Errors:   h.errors,
+		Waited:   h.waited,
+	}
 }
@@ -3,9 +3,12 @@ package backoff_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/dogmatiq/linger"
 	. "github.com/dogmatiq/linger/backoff"
+	"github.com/dogmatiq/linger/lingertest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -47,6 +50,102 @@ var _ = Describe("func Retry()", func() {
 		Expect(n).To(BeNumerically("==", 1))
 	})
 
+	It("returns an exhausted error if the strategy returns Stop", func() {
+		count := 0
+		var last error
+
+		n, err := Retry(
+			context.Background(),
+			FiniteSchedule(1*time.Nanosecond, 1*time.Nanosecond),
+			func(context.Context) error {
+				count++
+				last = fmt.Errorf("<error %d>", count)
+				return last
+			},
+		)
+
+		Expect(err).To(MatchError(ErrExhausted))
+		Expect(err).To(MatchError(last))
+		Expect(err).To(MatchError("backoff strategy exhausted after 3 failure(s): <error 3>"))
+		Expect(n).To(BeNumerically("==", 3))
+
+		var e *ExhaustedError
+		Expect(errors.As(err, &e)).To(BeTrue())
+		Expect(e.Failures).To(BeNumerically("==", 3))
+		Expect(e.Cause).To(Equal(last))
+	})
+
+	It("stops when all strategies combined using CoalesceStrategy() are exhausted", func() {
+		n, err := Retry(
+			context.Background(),
+			CoalesceStrategy(
+				FiniteSchedule(1*time.Nanosecond),
+				FiniteSchedule(1*time.Nanosecond, 1*time.Nanosecond),
+			),
+			func(context.Context) error {
+				return errors.New("<error>")
+			},
+		)
+
+		Expect(err).To(MatchError(ErrExhausted))
+		Expect(n).To(BeNumerically("==", 3))
+	})
+
+	It("returns a permanent error immediately, without wrapping", func() {
+		cause := errors.New("<error>")
+
+		n, err := Retry(
+			context.Background(),
+			Constant(1*time.Hour),
+			func(context.Context) error {
+				return Permanent(cause)
+			},
+		)
+
+		Expect(err).To(BeIdenticalTo(cause))
+		Expect(n).To(BeNumerically("==", 1))
+	})
+
+	It("returns a permanent error that is wrapped by another error", func() {
+		cause := errors.New("<error>")
+
+		_, err := Retry(
+			context.Background(),
+			Constant(1*time.Hour),
+			func(context.Context) error {
+				return fmt.Errorf("<outer>: %w", Permanent(cause))
+			},
+		)
+
+		Expect(err).To(BeIdenticalTo(cause))
+	})
+
+	It("returns errors classified as permanent by the WithPermanentIf() option immediately", func() {
+		cause := errors.New("<error>")
+		count := 0
+
+		n, err := Retry(
+			context.Background(),
+			Constant(1*time.Nanosecond),
+			func(context.Context) error {
+				count++
+				if count == 3 {
+					return fmt.Errorf("<outer>: %w", cause)
+				}
+				return errors.New("<transient>")
+			},
+			WithPermanentIf(func(err error) bool {
+				return false
+			}),
+			WithPermanentIf(func(err error) bool {
+				return errors.Is(err, cause)
+			}),
+		)
+
+		Expect(err).To(MatchError("<outer>: <error>"))
+		Expect(n).To(BeNumerically("==", 3))
+	})
+
 	It("uses the default strategy if none is provided", func() {
 		count := 0
 
@@ -66,4 +165,496 @@ var _ = Describe("func Retry()", func() {
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(n).To(BeNumerically("==", 1))
 	})
+
+	It("supports strategies created by a factory", func() {
+		count := 0
+
+		n, err := Retry(
+			context.Background(),
+			DecorrelatedJitter(1*time.Nanosecond, 1*time.Millisecond)(),
+			func(context.Context) error {
+				if count == 3 {
+					return nil
+				}
+
+				count++
+				return errors.New("<error>")
+			},
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(BeNumerically("==", 3))
+	})
+
+	It("uses the clock specified by the WithClock() option", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		result := make(chan uint, 1)
+		count := 0
+
+		go func() {
+			defer GinkgoRecover()
+
+			n, err := Retry(
+				context.Background(),
+				Constant(1*time.Hour),
+				func(context.Context) error {
+					if count == 2 {
+						return nil
+					}
+
+					count++
+					return errors.New("<error>")
+				},
+				WithClock(clock),
+			)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			result <- n
+		}()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(1 * time.Hour)
+		clock.BlockUntilWaiters(1)
+		clock.Advance(1 * time.Hour)
+
+		Eventually(result).Should(Receive(BeNumerically("==", 2)))
+	})
+
+	It("uses the clock carried by the context if no clock is specified", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		ctx := clock.WithContext(context.Background())
+		result := make(chan uint, 1)
+		count := 0
+
+		go func() {
+			defer GinkgoRecover()
+
+			n, err := Retry(
+				ctx,
+				Constant(1*time.Hour),
+				func(context.Context) error {
+					if count == 1 {
+						return nil
+					}
+
+					count++
+					return errors.New("<error>")
+				},
+			)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			result <- n
+		}()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(1 * time.Hour)
+
+		Eventually(result).Should(Receive(BeNumerically("==", 1)))
+	})
+})
+
+var _ = Describe("func RetryValue()", func() {
+	It("returns the value if the function succeeds", func() {
+		count := 0
+
+		v, n, err := RetryValue(
+			context.Background(),
+			Constant(1*time.Nanosecond),
+			func(context.Context) (string, error) {
+				if count == 3 {
+					return "<value>", nil
+				}
+
+				count++
+				return "<partial>", errors.New("<error>")
+			},
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).To(Equal("<value>"))
+		Expect(n).To(BeNumerically("==", 3))
+	})
+
+	It("returns the zero-value if the context is canceled", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
+		defer cancel()
+
+		v, n, err := RetryValue(
+			ctx,
+			Linear(10*time.Millisecond),
+			func(context.Context) (int, error) {
+				return 123, errors.New("<error>")
+			},
+		)
+
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(v).To(BeZero())
+		Expect(n).To(BeNumerically("==", 1))
+	})
+
+	It("uses the default strategy if none is provided", func() {
+		count := 0
+
+		v, n, err := RetryValue(
+			context.Background(),
+			nil,
+			func(context.Context) (int, error) {
+				if count == 1 {
+					return 123, nil
+				}
+
+				count++
+				return 0, errors.New("<error>")
+			},
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).To(Equal(123))
+		Expect(n).To(BeNumerically("==", 1))
+	})
+
+	It("supports the same options as Retry()", func() {
+		cause := errors.New("<error>")
+
+		v, n, err := RetryValue(
+			context.Background(),
+			Constant(1*time.Hour),
+			func(context.Context) (int, error) {
+				return 123, cause
+			},
+			WithPermanentIf(func(err error) bool {
+				return errors.Is(err, cause)
+			}),
+		)
+
+		Expect(err).To(BeIdenticalTo(cause))
+		Expect(v).To(BeZero())
+		Expect(n).To(BeNumerically("==", 1))
+	})
+})
+
+var _ = Describe("func Retry() (with the WithRetryError() option)", func() {
+	It("returns a *RetryError that describes the failures if the context is canceled", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		result := make(chan error, 1)
+		count := 0
+
+		go func() {
+			_, err := Retry(
+				ctx,
+				Constant(10*time.Second),
+				func(context.Context) error {
+					count++
+					return fmt.Errorf("<error %d>", count)
+				},
+				WithClock(clock),
+				WithRetryError(2),
+			)
+			result <- err
+		}()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(10 * time.Second)
+		clock.BlockUntilWaiters(1)
+		clock.Advance(10 * time.Second)
+		clock.BlockUntilWaiters(1)
+		clock.Advance(5 * time.Second)
+		cancel()
+
+		var err error
+		Eventually(result).Should(Receive(&err))
+
+		var re *RetryError
+		Expect(errors.As(err, &re)).To(BeTrue())
+		Expect(re.Err).To(Equal(context.Canceled))
+		Expect(re.Attempts).To(BeNumerically("==", 3))
+		Expect(re.Last).To(MatchError("<error 3>"))
+		Expect(re.Errors).To(HaveLen(2))
+		Expect(re.Errors[0]).To(MatchError("<error 2>"))
+		Expect(re.Errors[1]).To(MatchError("<error 3>"))
+		Expect(re.Waited).To(Equal(25 * time.Second))
+
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		Expect(errors.Is(err, re.Last)).To(BeTrue())
+		Expect(err).To(MatchError("failed after 3 attempt(s): context canceled (last error: <error 3>)"))
+	})
+
+	It("returns a *RetryError that matches ErrExhausted if the strategy is exhausted", func() {
+		cause := errors.New("<error>")
+
+		_, err := Retry(
+			context.Background(),
+			FiniteSchedule(1*time.Nanosecond),
+			func(context.Context) error {
+				return cause
+			},
+			WithRetryError(0),
+		)
+
+		var re *RetryError
+		Expect(errors.As(err, &re)).To(BeTrue())
+		Expect(re.Attempts).To(BeNumerically("==", 2))
+		Expect(re.Errors).To(BeEmpty())
+		Expect(errors.Is(err, ErrExhausted)).To(BeTrue())
+		Expect(errors.Is(err, cause)).To(BeTrue())
+	})
+
+	It("returns a permanent error without wrapping it", func() {
+		cause := errors.New("<error>")
+
+		n, err := Retry(
+			context.Background(),
+			Constant(1*time.Hour),
+			func(context.Context) error {
+				return Permanent(cause)
+			},
+			WithRetryError(10),
+		)
+
+		Expect(err).To(BeIdenticalTo(cause))
+		Expect(n).To(BeNumerically("==", 1))
+	})
+
+	It("returns an error classified as permanent by WithPermanentIf() without wrapping it", func() {
+		cause := errors.New("<error>")
+
+		_, err := Retry(
+			context.Background(),
+			Constant(1*time.Hour),
+			func(context.Context) error {
+				return cause
+			},
+			WithPermanentIf(func(err error) bool {
+				return err == cause
+			}),
+			WithRetryError(10),
+		)
+
+		Expect(err).To(BeIdenticalTo(cause))
+	})
+
+	It("returns nil if the function succeeds", func() {
+		_, err := Retry(
+			context.Background(),
+			Constant(1*time.Nanosecond),
+			func(context.Context) error {
+				return nil
+			},
+			WithRetryError(10),
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("func Retry() (with the WithAttemptTimeout() option)", func() {
+	It("applies a timeout to each attempt", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		var deadlines []time.Time
+
+		n, err := Retry(
+			context.Background(),
+			Constant(0),
+			func(ctx context.Context) error {
+				dl, ok := ctx.Deadline()
+				Expect(ok).To(BeTrue())
+				deadlines = append(deadlines, dl)
+
+				if len(deadlines) == 3 {
+					return nil
+				}
+
+				return errors.New("<error>")
+			},
+			WithClock(clock),
+			WithAttemptTimeout(Linear(10*time.Second)),
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(BeNumerically("==", 2))
+		Expect(deadlines).To(Equal([]time.Time{
+			clock.Now().Add(10 * time.Second),
+			clock.Now().Add(20 * time.Second),
+			clock.Now().Add(30 * time.Second),
+		}))
+	})
+
+	It("retries attempts that fail because their timeout is reached", func() {
+		count := 0
+
+		n, err := Retry(
+			context.Background(),
+			Constant(1*time.Nanosecond),
+			func(ctx context.Context) error {
+				count++
+				if count == 3 {
+					return nil
+				}
+
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			WithAttemptTimeout(Constant(1*time.Millisecond)),
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(BeNumerically("==", 2))
+	})
+
+	It("does not retry if the parent context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		n, err := Retry(
+			ctx,
+			Constant(1*time.Nanosecond),
+			func(ctx context.Context) error {
+				cancel()
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			WithAttemptTimeout(Constant(1*time.Hour)),
+		)
+
+		Expect(err).To(Equal(context.Canceled))
+		Expect(n).To(BeNumerically("==", 1))
+	})
+
+	It("does not apply a timeout if the duration is not positive", func() {
+		_, err := Retry(
+			context.Background(),
+			Constant(1*time.Nanosecond),
+			func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				Expect(ok).To(BeFalse())
+				return nil
+			},
+			WithAttemptTimeout(Constant(0)),
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("func Retry() (with deadline options)", func() {
+	var (
+		clock  *lingertest.FakeClock
+		ctx    context.Context
+		cancel func()
+		result chan error
+	)
+
+	BeforeEach(func() {
+		clock = lingertest.NewFakeClock(time.Now())
+		ctx, cancel = linger.ContextWithTimeoutC(context.Background(), clock, 100*time.Second)
+		result = make(chan error, 1)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	retry := func(options ...RetryOption) {
+		ctx, clock, result := ctx, clock, result
+		count := 0
+
+		go func() {
+			_, err := Retry(
+				ctx,
+				Constant(40*time.Second),
+				func(context.Context) error {
+					count++
+					return fmt.Errorf("<error %d>", count)
+				},
+				append(options, WithClock(clock))...,
+			)
+			result <- err
+		}()
+	}
+
+	// advance advances the clock by d once the retry loop is sleeping. The
+	// context's deadline timer is always pending, hence the 2 waiters.
+	advance := func(d time.Duration) {
+		clock.BlockUntilWaiters(2)
+		clock.Advance(d)
+	}
+
+	Describe("func WithStopBeforeDeadline()", func() {
+		It("gives up if the next attempt would not begin before the deadline", func() {
+			retry(WithStopBeforeDeadline(0))
+
+			advance(40 * time.Second)
+			advance(40 * time.Second)
+
+			var err error
+			Eventually(result).Should(Receive(&err))
+			Expect(err).To(MatchError(ErrExhausted))
+			Expect(err).To(MatchError("backoff strategy exhausted after 3 failure(s): <error 3>"))
+		})
+
+		It("accounts for the reserve duration", func() {
+			retry(WithStopBeforeDeadline(30 * time.Second))
+
+			advance(40 * time.Second)
+
+			var err error
+			Eventually(result).Should(Receive(&err))
+			Expect(err).To(MatchError("backoff strategy exhausted after 2 failure(s): <error 2>"))
+		})
+
+		It("has no effect if the context does not have a deadline", func() {
+			cancel()
+			ctx, cancel = context.WithCancel(context.Background())
+			retry(WithStopBeforeDeadline(0))
+
+			for i := 0; i < 5; i++ {
+				clock.BlockUntilWaiters(1)
+				clock.Advance(40 * time.Second)
+			}
+
+			Consistently(result).ShouldNot(Receive())
+		})
+
+		It("panics if the reserve is negative", func() {
+			Expect(func() {
+				WithStopBeforeDeadline(-1)
+			}).To(Panic())
+		})
+	})
+
+	Describe("func WithFitToDeadline()", func() {
+		It("shortens the final delay so that one last attempt fits", func() {
+			retry(WithFitToDeadline(5 * time.Second))
+
+			start := clock.Now()
+			advance(40 * time.Second)
+			advance(40 * time.Second)
+			advance(15 * time.Second)
+
+			var err error
+			Eventually(result).Should(Receive(&err))
+			Expect(err).To(MatchError("backoff strategy exhausted after 4 failure(s): <error 4>"))
+			Expect(clock.Now()).To(Equal(start.Add(95 * time.Second)))
+		})
+
+		It("gives up if there is less than the reserve remaining", func() {
+			retry(WithFitToDeadline(30 * time.Second))
+
+			advance(40 * time.Second)
+			advance(30 * time.Second)
+
+			var err error
+			Eventually(result).Should(Receive(&err))
+			Expect(err).To(MatchError("backoff strategy exhausted after 3 failure(s): <error 3>"))
+		})
+
+		It("panics if the reserve is not positive", func() {
+			Expect(func() {
+				WithFitToDeadline(0)
+			}).To(Panic())
+		})
+	})
 })
@@ -0,0 +1,126 @@
+package backoff
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dogmatiq/linger"
+)
+
+// RetryAfterer is an interface for errors that carry a hint as to how long to
+// wait before retrying the operation that produced them.
+//
+// The hint is typically provided by a server, such as via an HTTP Retry-After
+// header, or a gRPC RetryInfo error detail.
+type RetryAfterer interface {
+	error
+
+	// RetryAfter returns the duration to wait before retrying the operation.
+	RetryAfter() time.Duration
+}
+
+// HonorRetryAfter returns a strategy that uses the retry hint carried by the
+// failure's error, if present.
+//
+// The hint is obtained from the first error in the error chain that implements
+// RetryAfterer, as per errors.As(). The hint is capped at limit, then floored
+// at the result of s, such that the delay is never shorter than s would have
+// produced on its own. If limit is not positive the hint is not capped.
+//
+// If there is no hint, or s returns Stop, the result of s is returned
+// unchanged.
+func HonorRetryAfter(s Strategy, limit time.Duration) Strategy {
+	return func(err error, n uint) time.Duration {
+		d := s(err, n)
+		if d == Stop {
+			return d
+		}
+
+		var ra RetryAfterer
+		if !errors.As(err, &ra) {
+			return d
+		}
+
+		hint := ra.RetryAfter()
+		if limit > 0 && hint > limit {
+			hint = limit
+		}
+
+		if hint > d {
+			return hint
+		}
+
+		return d
+	}
+}
+
+// WithRetryAfter returns an error that wraps err and carries a hint that the
+// operation should be retried after d.
+//
+// The returned error implements RetryAfterer. It returns nil if err is nil.
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+
+	return &retryAfterError{err, d}
+}
+
+// ParseRetryAfter parses the value of an HTTP Retry-After header.
+//
+// The value may be either a number of seconds, or an HTTP date, in which case
+// the duration is computed relative to now. Durations in the past are reported
+// as zero. ok is false if the value can not be parsed.
+func ParseRetryAfter(v string, now time.Time) (d time.Duration, ok bool) {
+	v = strings.TrimSpace(v)
+
+	if secs, err := strconv.ParseUint(v, 10, 63); err == nil {
+		if secs > uint64(linger.MaxDuration/time.Second) {
+			return linger.MaxDuration, true
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	for _, layout := range httpDateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			if d := t.Sub(now); d > 0 {
+				return d, true
+			}
+
+			return 0, true
+		}
+	}
+
+	return 0, false
+}
+
+// httpDateLayouts are the time layouts accepted for HTTP dates, as per RFC
+// 9110 section 5.6.7. They are equivalent to those used by http.ParseTime(),
+// which is not used to avoid depending on the net/http package.
+var httpDateLayouts = []string{
+	"Mon, 02 Jan 2006 15:04:05 GMT", // IMF-fixdate, as per http.TimeFormat
+	time.RFC850,
+	time.ANSIC,
+}
+
+// retryAfterError is an implementation of RetryAfterer that wraps another
+// error.
+type retryAfterError struct {
+	err error
+	d   time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.d
+}
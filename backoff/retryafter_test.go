@@ -0,0 +1,118 @@
+package backoff_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dogmatiq/linger"
+	. "github.com/dogmatiq/linger/backoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("func HonorRetryAfter()", func() {
+	var strategy Strategy
+
+	BeforeEach(func() {
+		strategy = HonorRetryAfter(Constant(5*time.Second), 1*time.Minute)
+	})
+
+	It("uses the hint if it is longer than the result of the strategy", func() {
+		err := WithRetryAfter(errors.New("<error>"), 30*time.Second)
+		Expect(strategy(err, 0)).To(Equal(30 * time.Second))
+	})
+
+	It("finds the hint anywhere in the error chain", func() {
+		err := fmt.Errorf("<outer>: %w", WithRetryAfter(errors.New("<error>"), 30*time.Second))
+		Expect(strategy(err, 0)).To(Equal(30 * time.Second))
+	})
+
+	It("uses the result of the strategy if it is longer than the hint", func() {
+		err := WithRetryAfter(errors.New("<error>"), 1*time.Second)
+		Expect(strategy(err, 0)).To(Equal(5 * time.Second))
+	})
+
+	It("caps the hint at the limit", func() {
+		err := WithRetryAfter(errors.New("<error>"), 1*time.Hour)
+		Expect(strategy(err, 0)).To(Equal(1 * time.Minute))
+	})
+
+	It("does not cap the hint if the limit is not positive", func() {
+		s := HonorRetryAfter(Constant(5*time.Second), 0)
+		err := WithRetryAfter(errors.New("<error>"), 1*time.Hour)
+		Expect(s(err, 0)).To(Equal(1 * time.Hour))
+	})
+
+	It("uses the result of the strategy if there is no hint", func() {
+		Expect(strategy(errors.New("<error>"), 0)).To(Equal(5 * time.Second))
+		Expect(strategy(nil, 0)).To(Equal(5 * time.Second))
+	})
+
+	It("does not override Stop", func() {
+		s := HonorRetryAfter(FiniteSchedule(), 1*time.Minute)
+		err := WithRetryAfter(errors.New("<error>"), 30*time.Second)
+		Expect(s(err, 0)).To(Equal(Stop))
+	})
+})
+
+var _ = Describe("func WithRetryAfter()", func() {
+	It("returns an error that wraps the given error", func() {
+		cause := errors.New("<error>")
+		err := WithRetryAfter(cause, 30*time.Second)
+
+		Expect(err).To(MatchError("<error>"))
+		Expect(errors.Is(err, cause)).To(BeTrue())
+
+		var ra RetryAfterer
+		Expect(errors.As(err, &ra)).To(BeTrue())
+		Expect(ra.RetryAfter()).To(Equal(30 * time.Second))
+	})
+
+	It("returns nil if the error is nil", func() {
+		Expect(WithRetryAfter(nil, 30*time.Second)).To(BeNil())
+	})
+})
+
+var _ = Describe("func ParseRetryAfter()", func() {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	It("parses a number of seconds", func() {
+		d, ok := ParseRetryAfter(" 120 ", now)
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(2 * time.Minute))
+	})
+
+	It("saturates at linger.MaxDuration", func() {
+		d, ok := ParseRetryAfter("99999999999999999", now)
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(linger.MaxDuration))
+	})
+
+	DescribeTable(
+		"it parses an HTTP date",
+		func(v string) {
+			d, ok := ParseRetryAfter(v, now)
+			Expect(ok).To(BeTrue())
+			Expect(d).To(Equal(90 * time.Second))
+		},
+		Entry("IMF-fixdate", "Wed, 01 Jan 2020 00:01:30 GMT"),
+		Entry("RFC 850", "Wednesday, 01-Jan-20 00:01:30 GMT"),
+		Entry("ANSI C", "Wed Jan  1 00:01:30 2020"),
+	)
+
+	It("returns zero for an HTTP date in the past", func() {
+		d, ok := ParseRetryAfter("Tue, 31 Dec 2019 23:58:30 GMT", now)
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(time.Duration(0)))
+	})
+
+	It("returns false if the value is invalid", func() {
+		_, ok := ParseRetryAfter("<invalid>", now)
+		Expect(ok).To(BeFalse())
+
+		_, ok = ParseRetryAfter("-1", now)
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,77 @@
+package backoff
+
+import (
+	"errors"
+	"time"
+)
+
+// Rule is a rule used by ByError() to select a strategy based on the error
+// that describes an operation's failure.
+type Rule struct {
+	match    func(error) bool
+	strategy Strategy
+}
+
+// OnError returns a Rule that selects s if the failure's error matches target,
+// as per errors.Is().
+func OnError(target error, s Strategy) Rule {
+	return OnErrorFunc(
+		func(err error) bool {
+			return errors.Is(err, target)
+		},
+		s,
+	)
+}
+
+// OnErrorType returns a Rule that selects s if any error in the failure's
+// error chain is of type E, as per errors.As().
+func OnErrorType[E error](s Strategy) Rule {
+	return OnErrorFunc(
+		func(err error) bool {
+			var target E
+			return errors.As(err, &target)
+		},
+		s,
+	)
+}
+
+// OnErrorFunc returns a Rule that selects s if pred returns true for the
+// failure's error.
+//
+// pred is not called if the error is nil.
+func OnErrorFunc(pred func(error) bool, s Strategy) Rule {
+	if s == nil {
+		panic("the strategy must not be nil")
+	}
+
+	return Rule{pred, s}
+}
+
+// ByError returns a strategy that selects another strategy based on the error
+// that describes the operation's failure.
+//
+// The rules are checked in order. The strategy of the first rule that matches
+// the error is used. If no rules match, or the error is nil, def is used. If
+// def is nil, DefaultStrategy is used.
+//
+// n is passed to the selected strategy unchanged, so it is the number of
+// successive failures of any kind, not only those that match the rule.
+func ByError(def Strategy, rules ...Rule) Strategy {
+	if def == nil {
+		def = DefaultStrategy
+	}
+
+	rules = append([]Rule(nil), rules...)
+
+	return func(err error, n uint) time.Duration {
+		if err != nil {
+			for _, r := range rules {
+				if r.match(err) {
+					return r.strategy(err, n)
+				}
+			}
+		}
+
+		return def(err, n)
+	}
+}
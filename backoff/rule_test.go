@@ -0,0 +1,83 @@
+package backoff_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	. "github.com/dogmatiq/linger/backoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// rateLimitError is an error type used to test type-based rules.
+type rateLimitError struct{}
+
+func (rateLimitError) Error() string { return "<rate limited>" }
+
+var _ = Describe("func ByError()", func() {
+	var (
+		errTransient = errors.New("<transient>")
+		strategy     Strategy
+	)
+
+	BeforeEach(func() {
+		strategy = ByError(
+			Constant(1*time.Second),
+			OnErrorType[rateLimitError](Constant(1*time.Minute)),
+			OnError(errTransient, Exponential(10*time.Millisecond)),
+			OnErrorFunc(
+				func(err error) bool {
+					var netErr net.Error
+					return errors.As(err, &netErr) && netErr.Timeout()
+				},
+				Constant(5*time.Second),
+			),
+		)
+	})
+
+	It("selects the strategy of a rule that matches using errors.Is()", func() {
+		err := fmt.Errorf("<outer>: %w", errTransient)
+		Expect(strategy(err, 2)).To(Equal(40 * time.Millisecond))
+	})
+
+	It("selects the strategy of a rule that matches using errors.As()", func() {
+		err := fmt.Errorf("<outer>: %w", rateLimitError{})
+		Expect(strategy(err, 0)).To(Equal(1 * time.Minute))
+	})
+
+	It("selects the strategy of a rule that matches using a predicate", func() {
+		err := &net.DNSError{IsTimeout: true}
+		Expect(strategy(err, 0)).To(Equal(5 * time.Second))
+	})
+
+	It("uses the first matching rule", func() {
+		s := ByError(
+			Constant(1*time.Second),
+			OnError(errTransient, Constant(2*time.Second)),
+			OnError(errTransient, Constant(3*time.Second)),
+		)
+
+		Expect(s(errTransient, 0)).To(Equal(2 * time.Second))
+	})
+
+	It("uses the default strategy if no rules match", func() {
+		Expect(strategy(errors.New("<other>"), 0)).To(Equal(1 * time.Second))
+	})
+
+	It("uses the default strategy if the error is nil", func() {
+		Expect(strategy(nil, 0)).To(Equal(1 * time.Second))
+	})
+
+	It("uses DefaultStrategy if the default strategy is nil", func() {
+		s := ByError(nil)
+		Expect(s(nil, 0)).To(BeNumerically("<=", 3*time.Second))
+	})
+
+	It("panics if a rule's strategy is nil", func() {
+		Expect(func() {
+			OnError(errTransient, nil)
+		}).To(Panic())
+	})
+})
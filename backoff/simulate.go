@@ -0,0 +1,200 @@
+package backoff
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/dogmatiq/linger"
+)
+
+// MaxSimulationSamples is the maximum number of delays that Simulate() may
+// record, that is, the maximum product of its attempts and trials parameters.
+//
+// Every delay is retained in order to compute percentiles, so this limits the
+// memory used by a simulation to roughly 64 MiB.
+const MaxSimulationSamples = 1 << 22
+
+// Simulation is the result of simulating a strategy using Simulate().
+type Simulation struct {
+	// Trials is the number of times the strategy was run.
+	Trials uint
+
+	// Attempts contains a summary of the delays before each retry.
+	//
+	// The element at index n describes the delay after n+1 successive
+	// failures, that is, the value returned by the strategy when passed n.
+	// It is shorter than the number of attempts requested if the strategy
+	// returned Stop in every trial.
+	Attempts []AttemptSummary
+
+	// CapAttempt is the index into Attempts of the first retry whose median
+	// delay is the longest median delay of any retry.
+	//
+	// Attempts[CapAttempt].Cumulative summarizes the time spent waiting until
+	// the strategy reaches its cap, if Capped is true. It is zero if Attempts
+	// is empty.
+	CapAttempt int
+
+	// Capped is true if any retry after CapAttempt has the same median delay
+	// as the retry at CapAttempt.
+	//
+	// This indicates that the strategy reaches a cap, such as one imposed by
+	// linger.Limiter() or saturation at linger.MaxDuration, rather than still
+	// growing when the simulation ends.
+	Capped bool
+}
+
+// AttemptSummary describes the delays before a single retry across all trials
+// of a simulation.
+type AttemptSummary struct {
+	// Delay summarizes the delay before this retry.
+	Delay Summary
+
+	// Cumulative summarizes the total time spent waiting up to and including
+	// the delay before this retry.
+	Cumulative Summary
+}
+
+// Summary describes the distribution of a set of durations.
+//
+// The percentiles are computed using the nearest-rank method.
+type Summary struct {
+	// Samples is the number of durations in the set.
+	//
+	// It may be less than the number of trials if the strategy returned Stop
+	// in some trials.
+	Samples uint
+
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// Simulate runs the strategy s trials times, without sleeping, and summarizes
+// the delays it produces for up to the given number of attempts.
+//
+// It is intended to help choose a strategy's parameters, for example by
+// examining the expected cumulative wait after a number of failures, or its
+// percentiles under jitter. If s is nil, DefaultStrategy is used. A nil error
+// is passed to s.
+//
+// A trial ends early if s returns Stop. Negative delays are treated as zero, as
+// they are by linger.Sleep(). Cumulative durations saturate at
+// linger.MaxDuration rather than overflowing.
+//
+// It panics if attempts multiplied by trials exceeds MaxSimulationSamples.
+func Simulate(s Strategy, attempts, trials uint) Simulation {
+	if trials != 0 && attempts > MaxSimulationSamples/trials {
+		panic("the number of attempts multiplied by the number of trials must not exceed MaxSimulationSamples")
+	}
+
+	if s == nil {
+		s = DefaultStrategy
+	}
+
+	// The slices are grown as the trials progress, rather than being
+	// allocated up-front, as attempts may be large if there are no trials.
+	var delays, cumulative [][]time.Duration
+
+	for range trials {
+		var total time.Duration
+
+		for n := range attempts {
+			d := s(nil, n)
+			if d == Stop {
+				break
+			}
+
+			if d < 0 {
+				d = 0
+			}
+
+			if n == uint(len(delays)) {
+				delays = append(delays, nil)
+				cumulative = append(cumulative, nil)
+			}
+
+			total = addSaturating(total, d)
+			delays[n] = append(delays[n], d)
+			cumulative[n] = append(cumulative[n], total)
+		}
+	}
+
+	sim := Simulation{
+		Trials: trials,
+	}
+
+	for n := range delays {
+		a := AttemptSummary{
+			Delay:      summarize(delays[n]),
+			Cumulative: summarize(cumulative[n]),
+		}
+
+		switch {
+		case n == 0 || a.Delay.P50 > sim.Attempts[sim.CapAttempt].Delay.P50:
+			sim.CapAttempt = n
+			sim.Capped = false
+		case a.Delay.P50 == sim.Attempts[sim.CapAttempt].Delay.P50:
+			sim.Capped = true
+		}
+
+		sim.Attempts = append(sim.Attempts, a)
+	}
+
+	return sim
+}
+
+// summarize returns a summary of the given durations, which must not be
+// empty. It sorts the durations in-place.
+func summarize(durations []time.Duration) Summary {
+	slices.Sort(durations)
+
+	var sum float64
+	for _, d := range durations {
+		sum += float64(d)
+	}
+
+	count := len(durations)
+	mean := sum / float64(count)
+
+	s := Summary{
+		Samples: uint(count),
+		Min:     durations[0],
+		Mean:    linger.MaxDuration,
+		P50:     percentile(durations, 0.50),
+		P90:     percentile(durations, 0.90),
+		P99:     percentile(durations, 0.99),
+		Max:     durations[count-1],
+	}
+
+	if mean < float64(linger.MaxDuration) {
+		s.Mean = time.Duration(mean)
+	}
+
+	return s
+}
+
+// percentile returns the p-th percentile of the given sorted durations using
+// the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// addSaturating returns a + b, or linger.MaxDuration if the result would
+// overflow. a and b must not be negative.
+func addSaturating(a, b time.Duration) time.Duration {
+	if a > linger.MaxDuration-b {
+		return linger.MaxDuration
+	}
+
+	return a + b
+}
@@ -0,0 +1,176 @@
+package backoff_test
+
+import (
+	"math"
+	"time"
+
+	"github.com/dogmatiq/linger"
+	. "github.com/dogmatiq/linger/backoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("func Simulate()", func() {
+	It("summarizes the delays of a deterministic strategy", func() {
+		sim := Simulate(Linear(time.Second), 3, 10)
+
+		Expect(sim.Trials).To(BeNumerically("==", 10))
+		Expect(sim.Attempts).To(HaveLen(3))
+
+		Expect(sim.Attempts[2].Delay).To(Equal(Summary{
+			Samples: 10,
+			Min:     3 * time.Second,
+			Mean:    3 * time.Second,
+			P50:     3 * time.Second,
+			P90:     3 * time.Second,
+			P99:     3 * time.Second,
+			Max:     3 * time.Second,
+		}))
+
+		Expect(sim.Attempts[2].Cumulative).To(Equal(Summary{
+			Samples: 10,
+			Min:     6 * time.Second,
+			Mean:    6 * time.Second,
+			P50:     6 * time.Second,
+			P90:     6 * time.Second,
+			P99:     6 * time.Second,
+			Max:     6 * time.Second,
+		}))
+	})
+
+	It("computes percentiles using the nearest-rank method", func() {
+		var trial time.Duration
+		strategy := func(_ error, n uint) time.Duration {
+			if n == 0 {
+				trial++
+			}
+			return trial * time.Second
+		}
+
+		sim := Simulate(strategy, 1, 100)
+		s := sim.Attempts[0].Delay
+
+		Expect(s.Min).To(Equal(1 * time.Second))
+		Expect(s.Mean).To(Equal(50500 * time.Millisecond))
+		Expect(s.P50).To(Equal(50 * time.Second))
+		Expect(s.P90).To(Equal(90 * time.Second))
+		Expect(s.P99).To(Equal(99 * time.Second))
+		Expect(s.Max).To(Equal(100 * time.Second))
+	})
+
+	It("produces ordered summaries for a jittered strategy", func() {
+		strategy := DefaultStrategyWith(linger.NewSeededRandSource(123))
+		sim := Simulate(strategy, 10, 1000)
+
+		Expect(sim.Attempts).To(HaveLen(10))
+
+		for _, a := range sim.Attempts {
+			for _, s := range []Summary{a.Delay, a.Cumulative} {
+				Expect(s.Samples).To(BeNumerically("==", 1000))
+				Expect(s.Min).To(BeNumerically("<=", s.P50))
+				Expect(s.Mean).To(BeNumerically(">=", s.Min))
+				Expect(s.Mean).To(BeNumerically("<=", s.Max))
+				Expect(s.P50).To(BeNumerically("<=", s.P90))
+				Expect(s.P90).To(BeNumerically("<=", s.P99))
+				Expect(s.P99).To(BeNumerically("<=", s.Max))
+			}
+
+			Expect(a.Delay.Max).To(BeNumerically("<=", 1*time.Hour))
+		}
+	})
+
+	It("omits attempts after the strategy returns Stop", func() {
+		sim := Simulate(FiniteSchedule(time.Second, time.Minute), 5, 10)
+
+		Expect(sim.Attempts).To(HaveLen(2))
+		Expect(sim.Attempts[1].Cumulative.Max).To(Equal(time.Minute + time.Second))
+	})
+
+	It("counts only the trials that reach each attempt", func() {
+		trial := 0
+		strategy := func(_ error, n uint) time.Duration {
+			if n == 0 {
+				trial++
+			}
+			if n == 1 && trial%2 == 0 {
+				return Stop
+			}
+			return time.Second
+		}
+
+		sim := Simulate(strategy, 2, 10)
+
+		Expect(sim.Attempts[0].Delay.Samples).To(BeNumerically("==", 10))
+		Expect(sim.Attempts[1].Delay.Samples).To(BeNumerically("==", 5))
+	})
+
+	It("saturates the cumulative delay at linger.MaxDuration", func() {
+		sim := Simulate(Constant(linger.MaxDuration/2+1), 3, 2)
+
+		Expect(sim.Attempts[0].Cumulative.Max).To(Equal(linger.MaxDuration/2 + 1))
+		Expect(sim.Attempts[1].Cumulative.Max).To(Equal(linger.MaxDuration))
+		Expect(sim.Attempts[1].Cumulative.Mean).To(Equal(linger.MaxDuration))
+		Expect(sim.Attempts[2].Cumulative.Max).To(Equal(linger.MaxDuration))
+	})
+
+	It("treats negative delays as zero", func() {
+		sim := Simulate(Constant(-time.Second), 1, 1)
+
+		Expect(sim.Attempts[0].Delay.Max).To(Equal(time.Duration(0)))
+	})
+
+	It("returns no attempts if there are no trials", func() {
+		sim := Simulate(Linear(time.Second), 3, 0)
+
+		Expect(sim.Attempts).To(BeEmpty())
+		Expect(sim.CapAttempt).To(Equal(0))
+		Expect(sim.Capped).To(BeFalse())
+	})
+
+	It("reports when the strategy reaches its cap", func() {
+		sim := Simulate(
+			WithTransforms(
+				Exponential(time.Second),
+				linger.Limiter(0, 10*time.Second),
+			),
+			10,
+			1,
+		)
+
+		Expect(sim.Capped).To(BeTrue())
+		Expect(sim.CapAttempt).To(Equal(4))
+		Expect(sim.Attempts[sim.CapAttempt].Delay.P50).To(Equal(10 * time.Second))
+		Expect(sim.Attempts[sim.CapAttempt].Cumulative.P50).To(Equal(25 * time.Second))
+	})
+
+	It("reports when the strategy saturates at linger.MaxDuration", func() {
+		sim := Simulate(Exponential(time.Hour), 50, 1)
+
+		Expect(sim.Capped).To(BeTrue())
+		Expect(sim.Attempts[sim.CapAttempt].Delay.P50).To(Equal(linger.MaxDuration))
+		Expect(sim.Attempts[sim.CapAttempt-1].Delay.P50).To(BeNumerically("<", linger.MaxDuration))
+	})
+
+	It("does not report a cap if the strategy is still growing", func() {
+		sim := Simulate(Linear(time.Second), 5, 1)
+
+		Expect(sim.Capped).To(BeFalse())
+		Expect(sim.CapAttempt).To(Equal(4))
+	})
+
+	It("does not allocate space for attempts if there are no trials", func() {
+		sim := Simulate(Linear(time.Second), math.MaxUint, 0)
+
+		Expect(sim.Attempts).To(BeEmpty())
+	})
+
+	It("panics if the number of samples would exceed MaxSimulationSamples", func() {
+		Expect(func() {
+			Simulate(Linear(time.Second), MaxSimulationSamples+1, 1)
+		}).To(Panic())
+
+		Expect(func() {
+			Simulate(Linear(time.Second), math.MaxUint, 2)
+		}).To(Panic())
+	})
+})
@@ -2,6 +2,7 @@ package backoff
 
 import (
 	"math"
+	"sync"
 	"time"
 
 	"github.com/dogmatiq/linger"
@@ -17,6 +18,19 @@ var DefaultStrategy = WithTransforms(
 	linger.Limiter(0, 1*time.Hour),
 )
 
+// DefaultStrategyWith returns a strategy that is equivalent to DefaultStrategy,
+// except that it uses src as the source of randomness for its jitter.
+//
+// It can be used with linger.NewSeededRandSource() to produce a reproducible
+// sequence of delays.
+func DefaultStrategyWith(src linger.RandSource) Strategy {
+	return WithTransforms(
+		Exponential(3*time.Second),
+		linger.FullJitterWith(src),
+		linger.Limiter(0, 1*time.Hour),
+	)
+}
+
 // Strategy is a function for computing delays between attempts to perform some
 // application-defined operation.
 //
@@ -27,18 +41,53 @@ var DefaultStrategy = WithTransforms(
 // the failure indicated by err.
 type Strategy func(err error, n uint) time.Duration
 
+// Stop is a special duration that is returned by a Strategy to indicate that
+// no further attempts should be made.
+//
+// Strategies that combine other strategies, such as those returned by
+// CoalesceStrategy() and FirstStrategy(), fall back to the next strategy when
+// one returns Stop. They only return Stop if no strategy produces a suitable
+// duration.
+const Stop = linger.MinDuration
+
+// StrategyFactory is a function that returns a new Strategy for each sequence
+// of successive failures.
+//
+// It is used to implement stateful strategies, where each delay depends on the
+// delays that came before it. The strategies it returns must only be used for
+// a single sequence of failures. Each call to Retry() is a single sequence, so
+// a new strategy can be passed to each call, such as Retry(ctx, f(), fn).
+// A Counter creates a new strategy each time it is reset.
+type StrategyFactory func() Strategy
+
 // Exponential returns a Strategy that uses binary exponential backoff (BEB).
 //
-// The unit delay is doubled after each successive failure.
+// The unit delay is doubled after each successive failure. It is equivalent to
+// Geometric(unit, 2).
 func Exponential(unit time.Duration) Strategy {
+	return Geometric(unit, 2)
+}
+
+// Geometric returns a Strategy that increases the wait duration geometrically.
+//
+// The unit delay is multiplied by the given factor after each successive
+// failure. For example, a factor of 1.5 produces delays of 1, 1.5, 2.25, ...
+// times the unit.
+//
+// It panics if the unit is not positive, or the factor is less than 1.
+func Geometric(unit time.Duration, factor float64) Strategy {
 	if unit <= 0 {
 		panic("the unit duration must be positive")
 	}
 
+	if !(factor >= 1) {
+		panic("the factor must be greater than or equal to 1")
+	}
+
 	u := float64(unit)
 
 	return func(_ error, n uint) time.Duration {
-		scale := math.Pow(2, float64(n))
+		scale := math.Pow(factor, float64(n))
 		nanos := u * scale
 
 		// Overflow check.
@@ -50,6 +99,62 @@ func Exponential(unit time.Duration) Strategy {
 	}
 }
 
+// DecorrelatedJitter returns a StrategyFactory that produces strategies that
+// use "decorrelated jitter".
+//
+// Each delay is a random duration between the base duration and three times
+// the previous delay, capped at the given limit. The first delay is a random
+// duration between base and three times base.
+func DecorrelatedJitter(base, limit time.Duration) StrategyFactory {
+	return DecorrelatedJitterWith(base, limit, linger.GlobalRandSource)
+}
+
+// DecorrelatedJitterWith returns a StrategyFactory that produces strategies
+// that use "decorrelated jitter", using src as the source of randomness.
+//
+// It behaves like DecorrelatedJitter(), except that random values are obtained
+// from src.
+func DecorrelatedJitterWith(base, limit time.Duration, src linger.RandSource) StrategyFactory {
+	if base <= 0 {
+		panic("the base duration must be positive")
+	}
+
+	if limit < base {
+		panic("the limit must not be less than the base duration")
+	}
+
+	return func() Strategy {
+		var (
+			m    sync.Mutex
+			prev time.Duration
+		)
+
+		return func(_ error, n uint) time.Duration {
+			m.Lock()
+			defer m.Unlock()
+
+			// Restart the sequence if this is the first failure.
+			if n == 0 || prev == 0 {
+				prev = base
+			}
+
+			upper := linger.MaxDuration
+
+			// Overflow check: Only multiply if the result fits.
+			if prev <= linger.MaxDuration/3 {
+				upper = prev * 3
+			}
+
+			prev = linger.Shortest(
+				linger.RandWith(src, base, upper),
+				limit,
+			)
+
+			return prev
+		}
+	}
+}
+
 // Constant returns a Strategy that returns a fixed wait duration.
 func Constant(d time.Duration) Strategy {
 	return func(error, uint) time.Duration {
@@ -67,40 +172,160 @@ func Linear(unit time.Duration) Strategy {
 
 	return func(_ error, n uint) time.Duration {
 		mult := time.Duration(n) + 1
-		delay := mult * unit
+		return scale(unit, mult)
+	}
+}
 
-		// Overflow check: If delay is negative, there was clearly an overflow
-		// because both unit and mult are positive.
-		if delay < 0 {
-			return linger.MaxDuration
+// Fibonacci returns a Strategy that increases the wait duration according to
+// the Fibonacci sequence.
+//
+// The unit delay is multiplied by the (n+1)th Fibonacci number, where n is the
+// number of successive failures, producing delays of 1, 1, 2, 3, 5, 8, ...
+// times the unit.
+func Fibonacci(unit time.Duration) Strategy {
+	if unit <= 0 {
+		panic("the unit duration must be positive")
+	}
+
+	return func(_ error, n uint) time.Duration {
+		var prev, mult time.Duration = 0, 1
+
+		for i := uint(0); i < n; i++ {
+			// Overflow check: If the next number in the sequence can not be
+			// represented, the product can not be either.
+			if mult > linger.MaxDuration-prev {
+				return linger.MaxDuration
+			}
+
+			prev, mult = mult, prev+mult
 		}
 
-		// Overflow check: Dividing the delay by the unit value should give us
-		// back the multiplier that we used. If not, there was an overflow.
-		if delay/unit != mult {
-			return linger.MaxDuration
+		return scale(unit, mult)
+	}
+}
+
+// Polynomial returns a Strategy that increases the wait duration polynomially.
+//
+// The unit delay is multiplied by (n+1)^degree, where n is the number of
+// successive failures. A degree of 1 is equivalent to Linear(), and a degree
+// of 2 produces delays of 1, 4, 9, 16, ... times the unit.
+func Polynomial(unit time.Duration, degree uint) Strategy {
+	if unit <= 0 {
+		panic("the unit duration must be positive")
+	}
+
+	return func(_ error, n uint) time.Duration {
+		base := time.Duration(n) + 1
+		mult := time.Duration(1)
+
+		for i := uint(0); i < degree; i++ {
+			mult = scale(mult, base)
+
+			if mult == linger.MaxDuration {
+				return linger.MaxDuration
+			}
 		}
 
-		return delay
+		return scale(unit, mult)
+	}
+}
+
+// scale returns unit multiplied by mult, or linger.MaxDuration if the result
+// would overflow.
+//
+// unit and mult must both be positive.
+func scale(unit, mult time.Duration) time.Duration {
+	delay := mult * unit
+
+	// Overflow check: If delay is negative, there was clearly an overflow
+	// because both unit and mult are positive.
+	if delay < 0 {
+		return linger.MaxDuration
+	}
+
+	// Overflow check: Dividing the delay by the unit value should give us
+	// back the multiplier that we used. If not, there was an overflow.
+	if delay/unit != mult {
+		return linger.MaxDuration
+	}
+
+	return delay
+}
+
+// Schedule returns a Strategy that uses an explicit list of delays.
+//
+// The nth delay is used after n successive failures. Once n exceeds the number
+// of delays, the last delay is repeated indefinitely.
+//
+// It panics if no delays are given.
+func Schedule(delays ...time.Duration) Strategy {
+	if len(delays) == 0 {
+		panic("at least one delay must be provided")
+	}
+
+	delays = append([]time.Duration(nil), delays...)
+	last := uint(len(delays) - 1)
+
+	return func(_ error, n uint) time.Duration {
+		if n > last {
+			n = last
+		}
+
+		return delays[n]
+	}
+}
+
+// FiniteSchedule returns a Strategy that uses an explicit list of delays, then
+// returns Stop.
+//
+// The nth delay is used after n successive failures. Once n exceeds the number
+// of delays, the strategy returns Stop to indicate that no further attempts
+// should be made.
+func FiniteSchedule(delays ...time.Duration) Strategy {
+	delays = append([]time.Duration(nil), delays...)
+
+	return func(_ error, n uint) time.Duration {
+		if n >= uint(len(delays)) {
+			return Stop
+		}
+
+		return delays[n]
 	}
 }
 
 // WithTransforms returns a strategy that transforms the result of s using each
 // of the given transforms in order.
+//
+// If s returns Stop, it is returned without being transformed. The transforms
+// never produce Stop themselves. Transforms that saturate at
+// linger.MinDuration, such as linger.Multiplier() with a negative factor,
+// produce the next largest duration instead, which like any other negative
+// duration causes the next attempt to be made immediately.
 func WithTransforms(s Strategy, transforms ...linger.DurationTransform) Strategy {
 	return func(err error, n uint) time.Duration {
 		d := s(err, n)
+		if d == Stop {
+			return d
+		}
 
 		for _, x := range transforms {
 			d = x(d)
 		}
 
+		if d == Stop {
+			return Stop + 1
+		}
+
 		return d
 	}
 }
 
 // CoalesceStrategy returns a strategy that iterates over the given strategies
 // and runs them, returning the first positive duration.
+//
+// A strategy that returns Stop is skipped, allowing a finite strategy to be
+// followed by a fallback. If no strategy returns a positive duration and any
+// of them returned Stop, the returned strategy also returns Stop.
 func CoalesceStrategy(strategies ...Strategy) Strategy {
 	return FirstStrategy(linger.Positive, strategies...)
 }
@@ -108,14 +333,26 @@ func CoalesceStrategy(strategies ...Strategy) Strategy {
 // FirstStrategy returns a strategy that iterates over the given strategies
 // and runs them, returning the first duration which satisfies the predicate.
 // Return zero if no duration satisfies the predicate.
+//
+// Stop never satisfies the predicate. If no duration satisfies the predicate
+// and any of the strategies returned Stop, it returns Stop instead of zero.
 func FirstStrategy(p linger.DurationPredicate, strategies ...Strategy) Strategy {
 	return func(e error, n uint) time.Duration {
+		stop := false
+
 		for _, s := range strategies {
 			d := s(e, n)
-			if p(d) {
+			if d == Stop {
+				stop = true
+			} else if p(d) {
 				return d
 			}
 		}
+
+		if stop {
+			return Stop
+		}
+
 		return 0
 	}
 }
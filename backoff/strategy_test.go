@@ -1,15 +1,32 @@
 package backoff_test
 
 import (
+	"context"
+	"errors"
 	"math"
 	"time"
 
 	"github.com/dogmatiq/linger"
 	. "github.com/dogmatiq/linger/backoff"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
+var _ = Describe("func DefaultStrategyWith()", func() {
+	It("returns a strategy that produces the same delays for the same seed", func() {
+		a := DefaultStrategyWith(linger.NewSeededRandSource(123))
+		b := DefaultStrategyWith(linger.NewSeededRandSource(123))
+
+		delays := Take(a, 10)
+		Expect(delays).To(Equal(Take(b, 10)))
+
+		for _, d := range delays {
+			Expect(d).To(BeNumerically("<=", 1*time.Hour))
+		}
+	})
+})
+
 var _ = Describe("func Exponential()", func() {
 	It("returns a strategy that backs-off exponentially", func() {
 		strategy := Exponential(3 * time.Second)
@@ -46,6 +63,112 @@ var _ = Describe("func Exponential()", func() {
 
 })
 
+var _ = Describe("func Geometric()", func() {
+	It("returns a strategy that backs-off geometrically", func() {
+		strategy := Geometric(4*time.Second, 1.5)
+
+		Expect(strategy(nil, 0)).To(Equal(4 * time.Second))
+		Expect(strategy(nil, 1)).To(Equal(6 * time.Second))
+		Expect(strategy(nil, 2)).To(Equal(9 * time.Second))
+	})
+
+	It("returns a constant strategy if the factor is 1", func() {
+		strategy := Geometric(3*time.Second, 1)
+
+		Expect(strategy(nil, 0)).To(Equal(3 * time.Second))
+		Expect(strategy(nil, 100)).To(Equal(3 * time.Second))
+	})
+
+	It("is equivalent to Exponential() if the factor is 2", func() {
+		g := Geometric(3*time.Second, 2)
+		e := Exponential(3 * time.Second)
+
+		for n := uint(0); n < 100; n++ {
+			Expect(g(nil, n)).To(Equal(e(nil, n)))
+		}
+	})
+
+	It("panics if the unit is not positive", func() {
+		Expect(func() {
+			Geometric(0, 2)
+		}).To(Panic())
+	})
+
+	It("panics if the factor is less than 1", func() {
+		Expect(func() {
+			Geometric(1*time.Second, 0.5)
+		}).To(Panic())
+	})
+
+	It("panics if the factor is NaN", func() {
+		Expect(func() {
+			Geometric(1*time.Second, math.NaN())
+		}).To(Panic())
+	})
+
+	It("does not overflow the time.Duration type", func() {
+		strategy := Geometric(1*time.Second, 3)
+
+		Expect(strategy(nil, 100)).To(Equal(linger.MaxDuration))
+		Expect(strategy(nil, 1000)).To(Equal(linger.MaxDuration))
+	})
+})
+
+var _ = Describe("func DecorrelatedJitter()", func() {
+	It("returns strategies that produce delays within the expected bounds", func() {
+		base := 1 * time.Second
+		limit := 1 * time.Minute
+		strategy := DecorrelatedJitter(base, limit)()
+
+		prev := base
+		for n := uint(0); n < 100; n++ {
+			d := strategy(nil, n)
+			Expect(d).To(BeNumerically(">=", base))
+			Expect(d).To(BeNumerically("<=", linger.Shortest(prev*3, limit)))
+			prev = d
+		}
+	})
+
+	It("returns strategies that restart the sequence after a success", func() {
+		strategy := DecorrelatedJitter(1*time.Second, 1*time.Hour)()
+
+		for n := uint(0); n < 100; n++ {
+			strategy(nil, n)
+		}
+
+		Expect(strategy(nil, 0)).To(BeNumerically("<=", 3*time.Second))
+	})
+
+	It("does not overflow the time.Duration type", func() {
+		strategy := DecorrelatedJitter(linger.MaxDuration/2, linger.MaxDuration)()
+
+		for n := uint(0); n < 10; n++ {
+			Expect(strategy(nil, n)).To(BeNumerically(">=", linger.MaxDuration/2))
+		}
+	})
+
+	It("panics if the base is not positive", func() {
+		Expect(func() {
+			DecorrelatedJitter(0, 1*time.Second)
+		}).To(Panic())
+	})
+
+	It("panics if the limit is less than the base", func() {
+		Expect(func() {
+			DecorrelatedJitter(2*time.Second, 1*time.Second)
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("func DecorrelatedJitterWith()", func() {
+	It("returns strategies that produce the same delays for the same seed", func() {
+		a := DecorrelatedJitterWith(1*time.Second, 1*time.Hour, linger.NewSeededRandSource(123))()
+		b := DecorrelatedJitterWith(1*time.Second, 1*time.Hour, linger.NewSeededRandSource(123))()
+
+		Expect(Take(a, 10)).To(Equal(Take(b, 10)))
+	})
+})
+
 var _ = Describe("func Constant()", func() {
 	It("returns a strategy that returns a fixed duration", func() {
 		strategy := Constant(3 * time.Second)
@@ -95,6 +218,161 @@ var _ = Describe("func Linear()", func() {
 	})
 })
 
+var _ = Describe("func Fibonacci()", func() {
+	It("returns a strategy that follows the Fibonacci sequence", func() {
+		strategy := Fibonacci(3 * time.Second)
+
+		Expect(strategy(nil, 0)).To(Equal(3 * time.Second))
+		Expect(strategy(nil, 1)).To(Equal(3 * time.Second))
+		Expect(strategy(nil, 2)).To(Equal(6 * time.Second))
+		Expect(strategy(nil, 3)).To(Equal(9 * time.Second))
+		Expect(strategy(nil, 4)).To(Equal(15 * time.Second))
+		Expect(strategy(nil, 5)).To(Equal(24 * time.Second))
+	})
+
+	It("panics if the unit is zero", func() {
+		Expect(func() {
+			Fibonacci(0)
+		}).To(Panic())
+	})
+
+	It("panics if the unit is negative", func() {
+		Expect(func() {
+			Fibonacci(-1)
+		}).To(Panic())
+	})
+
+	It("does not overflow the time.Duration type", func() {
+		strategy := Fibonacci(1)
+
+		// F(92) is the largest Fibonacci number that fits in an int64.
+		Expect(strategy(nil, 91)).To(Equal(time.Duration(7540113804746346429)))
+
+		// Starts overflowing at F(93).
+		Expect(strategy(nil, 92)).To(Equal(linger.MaxDuration))
+
+		// Continues to return the capped value as n increases.
+		Expect(strategy(nil, 1000)).To(Equal(linger.MaxDuration))
+	})
+
+	It("does not overflow when multiplying by the unit", func() {
+		strategy := Fibonacci(linger.MaxDuration / 4)
+
+		Expect(strategy(nil, 3)).To(Equal(linger.MaxDuration / 4 * 3))
+		Expect(strategy(nil, 4)).To(Equal(linger.MaxDuration))
+	})
+})
+
+var _ = Describe("func Polynomial()", func() {
+	It("returns a strategy that returns a polynomially increasing duration", func() {
+		strategy := Polynomial(3*time.Second, 2)
+
+		Expect(strategy(nil, 0)).To(Equal(3 * time.Second))
+		Expect(strategy(nil, 1)).To(Equal(12 * time.Second))
+		Expect(strategy(nil, 2)).To(Equal(27 * time.Second))
+	})
+
+	It("is equivalent to Linear() if the degree is 1", func() {
+		p := Polynomial(3*time.Second, 1)
+		l := Linear(3 * time.Second)
+
+		for n := uint(0); n < 100; n++ {
+			Expect(p(nil, n)).To(Equal(l(nil, n)))
+		}
+	})
+
+	It("returns a constant duration if the degree is 0", func() {
+		strategy := Polynomial(3*time.Second, 0)
+
+		Expect(strategy(nil, 0)).To(Equal(3 * time.Second))
+		Expect(strategy(nil, 100)).To(Equal(3 * time.Second))
+	})
+
+	It("panics if the unit is zero", func() {
+		Expect(func() {
+			Polynomial(0, 2)
+		}).To(Panic())
+	})
+
+	It("panics if the unit is negative", func() {
+		Expect(func() {
+			Polynomial(-1, 2)
+		}).To(Panic())
+	})
+
+	It("does not overflow the time.Duration type", func() {
+		strategy := Polynomial(1, 3)
+
+		// No overflow at 2097151^3, which is just below 2^63.
+		Expect(strategy(nil, 2097150)).To(Equal(time.Duration(2097151 * 2097151 * 2097151)))
+
+		// Starts overflowing at 2097152^3, which is 2^63.
+		Expect(strategy(nil, 2097151)).To(Equal(linger.MaxDuration))
+
+		// Continues to return the capped value as n increases.
+		Expect(strategy(nil, 1<<40)).To(Equal(linger.MaxDuration))
+	})
+
+	It("does not overflow when multiplying by the unit", func() {
+		strategy := Polynomial(linger.MaxDuration/8, 2)
+
+		Expect(strategy(nil, 1)).To(Equal(linger.MaxDuration / 8 * 4))
+		Expect(strategy(nil, 2)).To(Equal(linger.MaxDuration))
+	})
+})
+
+var _ = Describe("func Schedule()", func() {
+	It("returns a strategy that returns the delays in order", func() {
+		strategy := Schedule(1*time.Second, 5*time.Second, 30*time.Second)
+
+		Expect(strategy(nil, 0)).To(Equal(1 * time.Second))
+		Expect(strategy(nil, 1)).To(Equal(5 * time.Second))
+		Expect(strategy(nil, 2)).To(Equal(30 * time.Second))
+	})
+
+	It("repeats the last delay indefinitely", func() {
+		strategy := Schedule(1*time.Second, 5*time.Minute)
+
+		Expect(strategy(nil, 2)).To(Equal(5 * time.Minute))
+		Expect(strategy(nil, 100)).To(Equal(5 * time.Minute))
+	})
+
+	It("is not affected by changes to the argument slice", func() {
+		delays := []time.Duration{1 * time.Second}
+		strategy := Schedule(delays...)
+		delays[0] = 2 * time.Second
+
+		Expect(strategy(nil, 0)).To(Equal(1 * time.Second))
+	})
+
+	It("panics if no delays are given", func() {
+		Expect(func() {
+			Schedule()
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("func FiniteSchedule()", func() {
+	It("returns a strategy that returns the delays in order, then stops", func() {
+		strategy := FiniteSchedule(1*time.Second, 5*time.Second)
+
+		Expect(strategy(nil, 0)).To(Equal(1 * time.Second))
+		Expect(strategy(nil, 1)).To(Equal(5 * time.Second))
+		Expect(strategy(nil, 2)).To(Equal(Stop))
+		Expect(strategy(nil, 100)).To(Equal(Stop))
+	})
+
+	It("can be combined with other strategies using CoalesceStrategy()", func() {
+		strategy := CoalesceStrategy(
+			FiniteSchedule(1*time.Second),
+			Constant(5*time.Minute),
+		)
+
+		Expect(strategy(nil, 0)).To(Equal(1 * time.Second))
+		Expect(strategy(nil, 1)).To(Equal(5 * time.Minute))
+	})
+})
+
 var _ = Describe("func WithTransform()", func() {
 	It("returns a strategy that that transforms the result of the input strategy", func() {
 		s := WithTransforms(
@@ -107,6 +385,46 @@ var _ = Describe("func WithTransform()", func() {
 		Expect(s(nil, 1)).To(Equal(20 * time.Second))
 		Expect(s(nil, 2)).To(Equal(25 * time.Second))
 	})
+
+	It("does not transform Stop", func() {
+		s := WithTransforms(
+			FiniteSchedule(10*time.Second),
+			linger.FullJitter,
+			linger.Limiter(0, 25*time.Second),
+		)
+
+		Expect(s(nil, 1)).To(Equal(Stop))
+	})
+
+	DescribeTable(
+		"it does not produce Stop when a transform saturates at linger.MinDuration",
+		func(s Strategy) {
+			d := s(nil, 0)
+			Expect(d).NotTo(Equal(Stop))
+			Expect(d).To(BeNumerically("<", 0))
+		},
+		Entry("bounded jitter", WithTransforms(Constant(linger.MaxDuration), linger.BoundedJitter(-2, -2))),
+		Entry("multiplier", WithTransforms(Constant(time.Hour), linger.Multiplier(-1e12))),
+	)
+
+	It("does not cause Retry() to give up when a transform saturates at linger.MinDuration", func() {
+		count := 0
+
+		n, err := Retry(
+			context.Background(),
+			WithTransforms(Constant(time.Hour), linger.Multiplier(-1e12)),
+			func(context.Context) error {
+				count++
+				if count < 3 {
+					return errors.New("<error>")
+				}
+				return nil
+			},
+		)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(BeNumerically("==", 2))
+	})
 })
 
 var _ = Describe("func CoalesceStrategy()", func() {
@@ -145,4 +463,14 @@ var _ = Describe("func CoalesceStrategy()", func() {
 		Expect(sC(nil, 2)).To(Equal(9 * time.Second))
 
 	})
+
+	It("returns Stop if no strategy yields a positive duration and any returns Stop", func() {
+		s := CoalesceStrategy(
+			Constant(0),
+			FiniteSchedule(1*time.Second),
+		)
+
+		Expect(s(nil, 0)).To(Equal(1 * time.Second))
+		Expect(s(nil, 5)).To(Equal(Stop))
+	})
 })
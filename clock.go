@@ -0,0 +1,99 @@
+package linger
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is an interface for measuring the passage of time.
+//
+// It allows the functions in this package to be used with time sources other
+// than the system clock, such as a fake clock within tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Until returns the duration until t.
+	Until(t time.Time) time.Duration
+
+	// NewTimer returns a new timer that fires after d has elapsed.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is an interface for a single event that occurs at some point in the
+// future, as measured by a Clock.
+type Timer interface {
+	// C returns the channel on which the current time is delivered when the
+	// timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing.
+	//
+	// It returns true if the call stops the timer, or false if the timer has
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// SystemClock is a Clock that uses the system's wall clock.
+var SystemClock Clock = systemClock{}
+
+// ContextWithClock returns a child of ctx that carries the clock c.
+//
+// Functions in this package that accept a nil Clock use the clock carried by
+// their context in preference to SystemClock. This allows a test to control
+// the timers used by code that calls Sleep(), SleepUntil(), etc without
+// modifying that code.
+func ContextWithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// ClockFromContext returns the clock carried by ctx.
+//
+// It returns SystemClock if ctx does not carry a clock.
+func ClockFromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok && c != nil {
+		return c
+	}
+
+	return SystemClock
+}
+
+// clockKey is the context key used to store the clock carried by a context.
+type clockKey struct{}
+
+// clockOrDefault returns c, or the clock carried by ctx if c is nil.
+func clockOrDefault(ctx context.Context, c Clock) Clock {
+	if c == nil {
+		return ClockFromContext(ctx)
+	}
+
+	return c
+}
+
+// systemClock is an implementation of Clock that uses the system's wall clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Until(t time.Time) time.Duration {
+	return time.Until(t)
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer is an implementation of Timer that wraps a *time.Timer.
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
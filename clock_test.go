@@ -0,0 +1,60 @@
+package linger_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/dogmatiq/linger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("var SystemClock", func() {
+	Describe("func Now()", func() {
+		It("returns the current time", func() {
+			Expect(SystemClock.Now()).To(BeTemporally("~", time.Now()))
+		})
+	})
+
+	Describe("func Until()", func() {
+		It("returns the duration until the given time", func() {
+			d := SystemClock.Until(time.Now().Add(10 * time.Second))
+			Expect(d).To(BeNumerically("~", 10*time.Second, 1*time.Second))
+		})
+	})
+
+	Describe("func NewTimer()", func() {
+		It("returns a timer that fires after the given duration", func() {
+			start := time.Now()
+			t := SystemClock.NewTimer(10 * time.Millisecond)
+			<-t.C()
+			elapsed := time.Since(start)
+
+			Expect(elapsed).To(BeNumerically(">=", 10*time.Millisecond))
+		})
+
+		It("returns a timer that can be stopped", func() {
+			t := SystemClock.NewTimer(10 * time.Second)
+			Expect(t.Stop()).To(BeTrue())
+			Expect(t.Stop()).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("func ClockFromContext()", func() {
+	It("returns the clock carried by the context", func() {
+		c := wrappedClock{SystemClock}
+		ctx := ContextWithClock(context.Background(), c)
+		Expect(ClockFromContext(ctx)).To(Equal(c))
+	})
+
+	It("returns the system clock if the context does not carry a clock", func() {
+		Expect(ClockFromContext(context.Background())).To(Equal(SystemClock))
+	})
+})
+
+// wrappedClock is a Clock that is not equal to SystemClock, but uses it to
+// measure time.
+type wrappedClock struct {
+	Clock
+}
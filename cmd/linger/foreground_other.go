@@ -0,0 +1,12 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+// inForeground returns true if linger is in the foreground process group of
+// the terminal connected to its standard input.
+//
+// Process groups are not supported on this platform, so it always returns
+// false.
+func inForeground() bool {
+	return false
+}
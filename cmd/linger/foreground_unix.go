@@ -0,0 +1,23 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// inForeground returns true if linger is in the foreground process group of
+// the terminal connected to its standard input.
+func inForeground() bool {
+	var pgrp int32
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(syscall.Stdin),
+		syscall.TIOCGPGRP,
+		uintptr(unsafe.Pointer(&pgrp)),
+	)
+
+	return errno == 0 && int(pgrp) == syscall.Getpgrp()
+}
@@ -0,0 +1,48 @@
+// Command linger provides command-line tools for working with the backoff
+// strategies provided by the linger module.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the subcommand described by args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	switch args[0] {
+	case "schedule":
+		return runSchedule(args[1:], stdout, stderr)
+	case "retry":
+		return runRetry(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "linger: unrecognized command %q\n\n", args[0])
+	usage(stderr)
+
+	return 2
+}
+
+// usage writes a description of the available subcommands to w.
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: linger <command> [arguments]
+
+Commands:
+  schedule    display the delays produced by one or more backoff strategies
+  retry       run a command until it succeeds, with backoff between attempts
+
+Run "linger <command> -help" for more information about a command.
+`)
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dogmatiq/linger/backoff"
+)
+
+// forwardedSignals is the set of signals that are forwarded to the command
+// run by the "retry" subcommand.
+var forwardedSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGTERM,
+	syscall.SIGHUP,
+	syscall.SIGQUIT,
+}
+
+// terminalSignals is the subset of forwardedSignals that a terminal sends to
+// every process in its foreground process group.
+//
+// When linger is in the foreground, the command is too, so these signals are
+// not forwarded, as the command would otherwise receive them twice. This is
+// also the convention for POSIX shells.
+var terminalSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGQUIT,
+}
+
+// retryConfig is the configuration for the "retry" subcommand.
+type retryConfig struct {
+	Strategy   backoff.Strategy
+	MaxElapsed time.Duration
+	RetryOn    exitCodes
+	Quiet      bool
+	Name       string
+	Args       []string
+
+	// InForeground reports whether linger is in the foreground process group
+	// of a terminal. If it is nil, linger is assumed not to be.
+	InForeground func() bool
+}
+
+// forward returns true if sig should be forwarded to the command.
+func (c retryConfig) forward(sig os.Signal) bool {
+	if !slices.Contains(terminalSignals, sig) {
+		return true
+	}
+
+	return c.InForeground == nil || !c.InForeground()
+}
+
+// runRetry executes the "retry" subcommand.
+func runRetry(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("retry", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var cfg retryConfig
+
+	spec := fs.String("strategy", "default", "the backoff strategy to use")
+	maxAttempts := fs.Uint("max-attempts", 0, "the maximum number of times to run the command, 0 means no limit")
+	maxElapsed := fs.Duration("max-elapsed", 0, "the maximum amount of time to spend retrying, 0 means no limit")
+	fs.Var(&cfg.RetryOn, "retry-on-exit", "a comma-separated list of exit statuses to retry, by default any non-zero status is retried")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "do not report failed attempts")
+
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: linger retry [options] [--] <command> [<argument>...]
+
+Run a command until it succeeds, waiting between attempts according to a
+backoff strategy.
+
+The command's standard input, output and error streams are inherited from
+linger. Interrupt, hang-up, terminate and quit signals are forwarded to the
+command, after which no further attempts are made. If linger is running in the
+foreground of a terminal, interrupt and quit signals are not forwarded, as the
+terminal already sends them to the command. linger exits with the command's
+last exit status.
+
+Options:
+`)
+		fs.PrintDefaults()
+		fmt.Fprint(fs.Output(), "\n"+strategyHelp+"\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "linger retry: a command is required")
+		fs.Usage()
+		return 2
+	}
+
+	s, err := parseStrategy(*spec)
+	if err != nil {
+		fmt.Fprintf(stderr, "linger retry: %s\n", err)
+		return 2
+	}
+
+	if *maxAttempts > 0 {
+		s = backoff.WithMaxAttempts(s, *maxAttempts)
+	}
+
+	if *maxElapsed < 0 {
+		fmt.Fprintln(stderr, "linger retry: the maximum elapsed time must not be negative")
+		return 2
+	}
+
+	cfg.Strategy = s
+	cfg.MaxElapsed = *maxElapsed
+	cfg.Name = fs.Arg(0)
+	cfg.Args = fs.Args()[1:]
+	cfg.InForeground = inForeground
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	return retryCommand(cfg, signals, os.Stdin, stdout, stderr)
+}
+
+// retryCommand runs the command described by cfg until it succeeds, and
+// returns its last exit status.
+//
+// Any signal received on signals prevents any further attempts, and is
+// forwarded to the command for as long as it runs, unless cfg.forward()
+// returns false.
+func retryCommand(
+	cfg retryConfig,
+	signals <-chan os.Signal,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		m       sync.Mutex
+		process *os.Process
+	)
+
+	// done is closed when retryCommand() returns. The goroutine can not stop
+	// when ctx is canceled, as the command may still be running, and must
+	// receive any subsequent signals.
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				m.Lock()
+				if process != nil && cfg.forward(sig) {
+					_ = process.Signal(sig)
+				}
+				cancel()
+				m.Unlock()
+			}
+		}
+	}()
+
+	status := 0
+
+	observer := backoff.ObserverFuncs{
+		Failure: func(err error, n uint, d time.Duration) time.Duration {
+			if !cfg.Quiet && d != backoff.Stop {
+				fmt.Fprintf(stderr, "linger retry: attempt %d failed (%s), retrying in %s\n", n+1, err, formatDuration(d))
+			}
+			return d
+		},
+	}
+
+	options := []backoff.RetryOption{
+		backoff.WithObserver(observer),
+	}
+
+	if cfg.MaxElapsed > 0 {
+		options = append(options, backoff.WithElapsedLimit(cfg.MaxElapsed))
+	}
+
+	n, err := backoff.Retry(
+		ctx,
+		cfg.Strategy,
+		func(ctx context.Context) error {
+			cmd := exec.Command(cfg.Name, cfg.Args...)
+			cmd.Stdin = stdin
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+
+			// Hold the lock while starting the command so that a signal can
+			// not be received between the check of ctx and the assignment of
+			// process, which would otherwise prevent it from being forwarded.
+			m.Lock()
+
+			if err := ctx.Err(); err != nil {
+				m.Unlock()
+				return backoff.Permanent(err)
+			}
+
+			if err := cmd.Start(); err != nil {
+				m.Unlock()
+				status = startFailureStatus(err)
+				return backoff.Permanent(err)
+			}
+
+			process = cmd.Process
+			m.Unlock()
+
+			_ = cmd.Wait()
+
+			m.Lock()
+			process = nil
+			m.Unlock()
+
+			status = exitStatus(cmd.ProcessState)
+
+			switch {
+			case status == 0:
+				return nil
+			case cfg.RetryOn.retry(status):
+				return exitStatusError(status)
+			default:
+				return backoff.Permanent(exitStatusError(status))
+			}
+		},
+		options...,
+	)
+
+	var (
+		statusErr exitStatusError
+		exhausted *backoff.ExhaustedError
+	)
+
+	switch {
+	case err == nil:
+	case errors.As(err, &exhausted):
+		if !cfg.Quiet {
+			fmt.Fprintf(stderr, "linger retry: giving up after %d attempt(s)\n", n)
+		}
+	case errors.As(err, &statusErr):
+		if !cfg.Quiet {
+			fmt.Fprintf(stderr, "linger retry: not retrying, %s is not retryable\n", statusErr)
+		}
+	case errors.Is(err, context.Canceled):
+		if !cfg.Quiet {
+			fmt.Fprintln(stderr, "linger retry: not retrying, interrupted by a signal")
+		}
+	default:
+		fmt.Fprintf(stderr, "linger retry: %s\n", err)
+	}
+
+	return status
+}
+
+// exitStatus returns the exit status of a process that has exited.
+//
+// If the process was terminated by a signal, it returns 128 plus the signal
+// number, as per the convention used by POSIX shells.
+func exitStatus(ps *os.ProcessState) int {
+	if code := ps.ExitCode(); code != -1 {
+		return code
+	}
+
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+
+	return 1
+}
+
+// startFailureStatus returns the exit status to use when a command can not be
+// started because of err, as per the convention used by POSIX shells.
+func startFailureStatus(err error) int {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+		return 127
+	}
+
+	return 126
+}
+
+// exitStatusError is an error that indicates that a command exited with a
+// non-zero status.
+type exitStatusError int
+
+func (e exitStatusError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// exitCodes is a flag.Value that parses a comma-separated list of exit
+// statuses.
+type exitCodes map[int]struct{}
+
+// retry returns true if a command that exits with the given non-zero status
+// should be retried.
+func (c exitCodes) retry(status int) bool {
+	if len(c) == 0 {
+		return true
+	}
+
+	_, ok := c[status]
+	return ok
+}
+
+func (c *exitCodes) Set(v string) error {
+	if *c == nil {
+		*c = exitCodes{}
+	}
+
+	for _, s := range strings.Split(v, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || code < 1 || code > 255 {
+			return fmt.Errorf("%q is not a non-zero exit status", s)
+		}
+
+		(*c)[code] = struct{}{}
+	}
+
+	return nil
+}
+
+func (c *exitCodes) String() string {
+	if c == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, code := range slices.Sorted(maps.Keys(*c)) {
+		parts = append(parts, strconv.Itoa(code))
+	}
+
+	return strings.Join(parts, ",")
+}
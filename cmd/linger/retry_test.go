@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/dogmatiq/linger/backoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("func runRetry()", func() {
+	var stdout, stderr *gbytes.Buffer
+
+	BeforeEach(func() {
+		stdout = gbytes.NewBuffer()
+		stderr = gbytes.NewBuffer()
+	})
+
+	It("runs the command until it succeeds", func() {
+		dir, err := os.MkdirTemp("", "linger-")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		counter := filepath.Join(dir, "counter")
+
+		code := run(
+			[]string{
+				"retry", "-strategy", "constant:1ms", "--",
+				"sh", "-c", `n=$(cat "$0" 2>/dev/null || echo 0); n=$((n+1)); echo $n > "$0"; echo "attempt $n"; [ $n -ge 3 ]`, counter,
+			},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(0))
+		Expect(string(stdout.Contents())).To(Equal("attempt 1\nattempt 2\nattempt 3\n"))
+		Expect(string(stderr.Contents())).To(Equal(
+			"linger retry: attempt 1 failed (exit status 1), retrying in 1ms\n" +
+				"linger retry: attempt 2 failed (exit status 1), retrying in 1ms\n",
+		))
+	})
+
+	It("exits with the command's last exit status when the maximum number of attempts is reached", func() {
+		code := run(
+			[]string{"retry", "-strategy", "constant:1ms", "-max-attempts", "3", "--", "sh", "-c", "echo attempt; exit 3"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(3))
+		Expect(string(stdout.Contents())).To(Equal("attempt\nattempt\nattempt\n"))
+		Expect(stderr).To(gbytes.Say(`giving up after 3 attempt\(s\)`))
+	})
+
+	It("stops retrying when the maximum elapsed time is reached", func() {
+		code := run(
+			[]string{"retry", "-strategy", "constant:20ms", "-max-elapsed", "50ms", "--", "sh", "-c", "exit 4"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(4))
+		Expect(stderr).To(gbytes.Say(`giving up after \d+ attempt\(s\)`))
+	})
+
+	It("retries only the given exit statuses", func() {
+		code := run(
+			[]string{"retry", "-strategy", "constant:1ms", "-retry-on-exit", "1,75", "-max-attempts", "5", "--", "sh", "-c", "echo attempt; exit 3"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(3))
+		Expect(string(stdout.Contents())).To(Equal("attempt\n"))
+		Expect(stderr).To(gbytes.Say("exit status 3 is not retryable"))
+	})
+
+	It("retries the given exit statuses", func() {
+		code := run(
+			[]string{"retry", "-strategy", "constant:1ms", "-retry-on-exit", "1,75", "-max-attempts", "2", "--", "sh", "-c", "echo attempt; exit 75"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(75))
+		Expect(string(stdout.Contents())).To(Equal("attempt\nattempt\n"))
+	})
+
+	It("does not report failed attempts if the -quiet flag is given", func() {
+		code := run(
+			[]string{"retry", "-quiet", "-strategy", "constant:1ms", "-max-attempts", "2", "--", "false"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(1))
+		Expect(stderr.Contents()).To(BeEmpty())
+	})
+
+	It("exits with status 127 if the command does not exist", func() {
+		code := run(
+			[]string{"retry", "--", "/does/not/exist"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(127))
+		Expect(stderr).To(gbytes.Say("/does/not/exist"))
+	})
+
+	DescribeTable(
+		"it fails if the arguments are invalid",
+		func(args []string, expect string) {
+			code := run(append([]string{"retry"}, args...), stdout, stderr)
+
+			Expect(code).To(Equal(2))
+			Expect(string(stderr.Contents())).To(ContainSubstring(expect))
+		},
+		Entry("no command", []string{"-strategy", "constant:1s"}, "a command is required"),
+		Entry("invalid strategy", []string{"-strategy", "quadratic:1s", "true"}, `unrecognized strategy kind "quadratic"`),
+		Entry("invalid exit status", []string{"-retry-on-exit", "1,x", "true"}, `"x" is not a non-zero exit status`),
+		Entry("zero exit status", []string{"-retry-on-exit", "0", "true"}, `"0" is not a non-zero exit status`),
+		Entry("negative max elapsed", []string{"-max-elapsed", "-1s", "true"}, "must not be negative"),
+	)
+})
+
+var _ = Describe("func retryCommand()", func() {
+	var (
+		signals        chan os.Signal
+		stdout, stderr *gbytes.Buffer
+		cfg            retryConfig
+	)
+
+	BeforeEach(func() {
+		signals = make(chan os.Signal, 1)
+		stdout = gbytes.NewBuffer()
+		stderr = gbytes.NewBuffer()
+
+		cfg = retryConfig{
+			Strategy: backoff.Constant(time.Hour),
+			Name:     "sh",
+		}
+	})
+
+	It("forwards signals to the command and does not retry", func() {
+		cfg.Args = []string{"-c", `trap 'exit 42' TERM; echo ready; while :; do sleep 0.01; done`}
+
+		result := make(chan int, 1)
+		go func() {
+			result <- retryCommand(cfg, signals, &bytes.Buffer{}, stdout, stderr)
+		}()
+
+		Eventually(stdout).Should(gbytes.Say("ready"))
+		signals <- syscall.SIGTERM
+
+		Eventually(result, 5*time.Second).Should(Receive(Equal(42)))
+		Expect(stderr).To(gbytes.Say("interrupted by a signal"))
+	})
+
+	It("forwards signals that are received after the first", func() {
+		cfg.Args = []string{"-c", `trap 'echo term; n=$((n+1)); [ $n -ge 2 ] && exit 42' TERM; echo ready; while :; do sleep 0.01; done`}
+
+		result := make(chan int, 1)
+		go func() {
+			result <- retryCommand(cfg, signals, &bytes.Buffer{}, stdout, stderr)
+		}()
+
+		Eventually(stdout).Should(gbytes.Say("ready"))
+		signals <- syscall.SIGTERM
+		Eventually(stdout, 5*time.Second).Should(gbytes.Say("term"))
+		signals <- syscall.SIGTERM
+
+		Eventually(result, 5*time.Second).Should(Receive(Equal(42)))
+	})
+
+	It("forwards interrupt signals if linger is not in the foreground of a terminal", func() {
+		cfg.Args = []string{"-c", `trap 'exit 42' INT; echo ready; while :; do sleep 0.01; done`}
+		cfg.InForeground = func() bool { return false }
+
+		result := make(chan int, 1)
+		go func() {
+			result <- retryCommand(cfg, signals, &bytes.Buffer{}, stdout, stderr)
+		}()
+
+		Eventually(stdout).Should(gbytes.Say("ready"))
+		signals <- os.Interrupt
+
+		Eventually(result, 5*time.Second).Should(Receive(Equal(42)))
+		Expect(stderr).To(gbytes.Say("interrupted by a signal"))
+	})
+
+	It("does not forward signals that the terminal sends to the command if linger is in the foreground", func() {
+		cfg.Args = []string{"-c", `trap 'exit 42' INT; trap 'exit 43' TERM; echo ready; while :; do sleep 0.01; done`}
+		cfg.InForeground = func() bool { return true }
+
+		result := make(chan int, 1)
+		go func() {
+			result <- retryCommand(cfg, signals, &bytes.Buffer{}, stdout, stderr)
+		}()
+
+		Eventually(stdout).Should(gbytes.Say("ready"))
+		signals <- os.Interrupt
+		Consistently(result, 200*time.Millisecond).ShouldNot(Receive())
+
+		signals <- syscall.SIGTERM
+		Eventually(result, 5*time.Second).Should(Receive(Equal(43)))
+		Expect(stderr).To(gbytes.Say("interrupted by a signal"))
+	})
+
+	It("reports the exit status of a command that is terminated by a signal", func() {
+		cfg.Args = []string{"-c", `echo ready; while :; do sleep 0.01; done`}
+
+		result := make(chan int, 1)
+		go func() {
+			result <- retryCommand(cfg, signals, &bytes.Buffer{}, stdout, stderr)
+		}()
+
+		Eventually(stdout).Should(gbytes.Say("ready"))
+		signals <- syscall.SIGTERM
+
+		Eventually(result, 5*time.Second).Should(Receive(Equal(128 + int(syscall.SIGTERM))))
+	})
+
+	It("stops waiting between attempts when a signal is received", func() {
+		cfg.Args = []string{"-c", "exit 5"}
+
+		result := make(chan int, 1)
+		go func() {
+			result <- retryCommand(cfg, signals, &bytes.Buffer{}, stdout, stderr)
+		}()
+
+		Eventually(stderr).Should(gbytes.Say("retrying in 1h0m0s"))
+		signals <- os.Interrupt
+
+		Eventually(result).Should(Receive(Equal(5)))
+	})
+})
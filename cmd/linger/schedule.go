@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dogmatiq/linger/backoff"
+)
+
+// histogramWidth is the maximum number of characters in a histogram bar.
+const histogramWidth = 50
+
+// simulation is the result of simulating a single strategy.
+type simulation struct {
+	Spec      string
+	Requested uint
+	backoff.Simulation
+}
+
+// runSchedule executes the "schedule" subcommand.
+func runSchedule(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	attempts := fs.Uint("attempts", 10, "the number of retries to display")
+	trials := fs.Uint("trials", 1000, "the number of times to run each strategy, to account for jitter")
+	format := fs.String("format", "table", "the output format, one of table, histogram or csv")
+
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: linger schedule [options] <strategy> [<strategy>...]
+
+Display the delays between retries produced by each strategy.
+
+Each strategy is run many times to account for jitter. The table and
+histogram formats show the median (p50) and 99th percentile (p99) of the
+delay before each retry, and of the total time spent waiting up to that
+point. The CSV format shows each strategy side by side, in seconds.
+
+Options:
+`)
+		fs.PrintDefaults()
+		fmt.Fprint(fs.Output(), "\n"+strategyHelp+"\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "linger schedule: at least one strategy is required")
+		fs.Usage()
+		return 2
+	}
+
+	var write func(io.Writer, []simulation) error
+
+	switch *format {
+	case "table":
+		write = writeTable
+	case "histogram":
+		write = writeHistogram
+	case "csv":
+		write = writeCSV
+	default:
+		fmt.Fprintf(stderr, "linger schedule: unrecognized format %q\n", *format)
+		return 2
+	}
+
+	if *trials == 0 {
+		fmt.Fprintln(stderr, "linger schedule: the number of trials must be positive")
+		return 2
+	}
+
+	if *attempts > backoff.MaxSimulationSamples / *trials {
+		fmt.Fprintf(
+			stderr,
+			"linger schedule: the number of attempts multiplied by the number of trials must not exceed %d\n",
+			backoff.MaxSimulationSamples,
+		)
+		return 2
+	}
+
+	var sims []simulation
+
+	for _, spec := range fs.Args() {
+		s, err := parseStrategy(spec)
+		if err != nil {
+			fmt.Fprintf(stderr, "linger schedule: %s\n", err)
+			return 2
+		}
+
+		sims = append(
+			sims,
+			simulation{
+				spec,
+				*attempts,
+				backoff.Simulate(s, *attempts, *trials),
+			},
+		)
+	}
+
+	if err := write(stdout, sims); err != nil {
+		fmt.Fprintf(stderr, "linger schedule: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// writeTable writes a table of the delays in each simulation to w.
+func writeTable(w io.Writer, sims []simulation) error {
+	for i, sim := range sims {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "%s\n\n", sim.Spec)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RETRY\tDELAY (P50)\tDELAY (P99)\tTOTAL (P50)\tTOTAL (P99)")
+
+		for n, a := range sim.Attempts {
+			fmt.Fprintf(
+				tw,
+				"%d\t%s\t%s\t%s\t%s\n",
+				n+1,
+				formatDuration(a.Delay.P50),
+				formatDuration(a.Delay.P99),
+				formatDuration(a.Cumulative.P50),
+				formatDuration(a.Cumulative.P99),
+			)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		writeStopNote(w, sim)
+	}
+
+	return nil
+}
+
+// writeHistogram writes a bar chart of the delays in each simulation to w.
+//
+// All of the charts use the same scale so that the strategies can be compared.
+func writeHistogram(w io.Writer, sims []simulation) error {
+	var longest time.Duration
+	for _, sim := range sims {
+		for _, a := range sim.Attempts {
+			longest = max(longest, a.Delay.P99)
+		}
+	}
+
+	fmt.Fprintf(w, "# = median delay, - = 99th percentile delay, %s = full width\n", formatDuration(longest))
+
+	for _, sim := range sims {
+		fmt.Fprintf(w, "\n%s\n\n", sim.Spec)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+		for n, a := range sim.Attempts {
+			p50 := scale(a.Delay.P50, longest)
+			p99 := max(scale(a.Delay.P99, longest), p50)
+
+			fmt.Fprintf(
+				tw,
+				"%d\t%s\t%s\n",
+				n+1,
+				strings.Repeat("#", p50)+strings.Repeat("-", p99-p50)+strings.Repeat(" ", histogramWidth-p99),
+				formatDuration(a.Delay.P50),
+			)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		writeStopNote(w, sim)
+	}
+
+	return nil
+}
+
+// writeCSV writes the delays in each simulation to w as CSV, with the
+// simulations side by side.
+func writeCSV(w io.Writer, sims []simulation) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"retry"}
+	rows := 0
+
+	for _, sim := range sims {
+		header = append(
+			header,
+			sim.Spec+" delay p50",
+			sim.Spec+" delay p99",
+			sim.Spec+" total p50",
+			sim.Spec+" total p99",
+		)
+
+		rows = max(rows, len(sim.Attempts))
+	}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for n := range rows {
+		record := []string{strconv.Itoa(n + 1)}
+
+		for _, sim := range sims {
+			if n >= len(sim.Attempts) {
+				record = append(record, "", "", "", "")
+				continue
+			}
+
+			a := sim.Attempts[n]
+			record = append(
+				record,
+				formatSeconds(a.Delay.P50),
+				formatSeconds(a.Delay.P99),
+				formatSeconds(a.Cumulative.P50),
+				formatSeconds(a.Cumulative.P99),
+			)
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeStopNote writes a note to w if the strategy in sim stops retrying
+// before the requested number of retries.
+func writeStopNote(w io.Writer, sim simulation) {
+	n := uint(len(sim.Attempts))
+
+	switch {
+	case n >= sim.Requested:
+	case n == 0:
+		fmt.Fprintln(w, "(the strategy never retries)")
+	case n == 1:
+		fmt.Fprintln(w, "(the strategy stops after 1 retry)")
+	default:
+		fmt.Fprintf(w, "(the strategy stops after %d retries)\n", n)
+	}
+}
+
+// scale returns the number of histogram characters that represent d, where
+// longest is represented by the full width of the histogram.
+func scale(d, longest time.Duration) int {
+	if longest <= 0 {
+		return 0
+	}
+
+	return int(math.Round(float64(d) / float64(longest) * histogramWidth))
+}
+
+// formatDuration returns a human-readable representation of d, rounded to a
+// precision suitable for display.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Minute:
+		d = d.Round(time.Second)
+	case d >= time.Second:
+		d = d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		d = d.Round(time.Microsecond)
+	}
+
+	return d.String()
+}
+
+// formatSeconds returns d as a decimal number of seconds.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("func runSchedule()", func() {
+	var stdout, stderr *bytes.Buffer
+
+	BeforeEach(func() {
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	It("writes a table of delays", func() {
+		code := run(
+			[]string{"schedule", "-attempts", "3", "exponential:1s", "finite:1s"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(0))
+		Expect(stderr.String()).To(BeEmpty())
+		Expect(stdout.String()).To(Equal(strings.Join(
+			[]string{
+				"exponential:1s",
+				"",
+				"RETRY  DELAY (P50)  DELAY (P99)  TOTAL (P50)  TOTAL (P99)",
+				"1      1s           1s           1s           1s",
+				"2      2s           2s           3s           3s",
+				"3      4s           4s           7s           7s",
+				"",
+				"finite:1s",
+				"",
+				"RETRY  DELAY (P50)  DELAY (P99)  TOTAL (P50)  TOTAL (P99)",
+				"1      1s           1s           1s           1s",
+				"(the strategy stops after 1 retry)",
+				"",
+			},
+			"\n",
+		)))
+	})
+
+	It("writes a histogram of delays", func() {
+		code := run(
+			[]string{"schedule", "-attempts", "2", "-format", "histogram", "linear:1s"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(Equal(strings.Join(
+			[]string{
+				"# = median delay, - = 99th percentile delay, 2s = full width",
+				"",
+				"linear:1s",
+				"",
+				"1  " + strings.Repeat("#", 25) + strings.Repeat(" ", 25) + "  1s",
+				"2  " + strings.Repeat("#", 50) + "  2s",
+				"",
+			},
+			"\n",
+		)))
+	})
+
+	It("writes the strategies side by side as CSV", func() {
+		code := run(
+			[]string{"schedule", "-attempts", "2", "-format", "csv", "linear:1s,limit=1s", "finite:500ms"},
+			stdout,
+			stderr,
+		)
+
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(Equal(strings.Join(
+			[]string{
+				`retry,"linear:1s,limit=1s delay p50","linear:1s,limit=1s delay p99","linear:1s,limit=1s total p50","linear:1s,limit=1s total p99",finite:500ms delay p50,finite:500ms delay p99,finite:500ms total p50,finite:500ms total p99`,
+				"1,1,1,1,1,0.5,0.5,0.5,0.5",
+				"2,1,1,2,2,,,,",
+				"",
+			},
+			"\n",
+		)))
+	})
+
+	It("fails if no strategy is given", func() {
+		code := run([]string{"schedule"}, stdout, stderr)
+
+		Expect(code).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring("at least one strategy is required"))
+	})
+
+	It("fails if a strategy is invalid", func() {
+		code := run([]string{"schedule", "quadratic:1s"}, stdout, stderr)
+
+		Expect(code).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring(`unrecognized strategy kind "quadratic"`))
+	})
+
+	DescribeTable(
+		"it fails if the simulation would be too large",
+		func(attempts, trials string) {
+			code := run([]string{"schedule", "-attempts", attempts, "-trials", trials, "linear:1s"}, stdout, stderr)
+
+			Expect(code).To(Equal(2))
+			Expect(stderr.String()).To(ContainSubstring("must not exceed"))
+		},
+		Entry("maximum attempts", "18446744073709551615", "1000"),
+		Entry("maximum trials", "10", "18446744073709551615"),
+		Entry("large product", "100000", "100000"),
+	)
+
+	It("fails if the format is invalid", func() {
+		code := run([]string{"schedule", "-format", "xml", "linear:1s"}, stdout, stderr)
+
+		Expect(code).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring(`unrecognized format "xml"`))
+	})
+})
+
+var _ = Describe("func run()", func() {
+	It("fails if the command is not recognized", func() {
+		stderr := &bytes.Buffer{}
+		code := run([]string{"frobnicate"}, &bytes.Buffer{}, stderr)
+
+		Expect(code).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring(`unrecognized command "frobnicate"`))
+	})
+
+	It("fails if no command is given", func() {
+		stderr := &bytes.Buffer{}
+		code := run(nil, &bytes.Buffer{}, stderr)
+
+		Expect(code).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring("Usage: linger <command>"))
+	})
+})
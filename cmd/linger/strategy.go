@@ -0,0 +1,361 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dogmatiq/linger"
+	"github.com/dogmatiq/linger/backoff"
+)
+
+// strategyHelp describes the syntax accepted by parseStrategy().
+const strategyHelp = `A strategy is described as <kind>[:<arg>][,<option>=<value>...], for example
+"exponential:3s,jitter=full,limit=1h".
+
+Kinds:
+  default               the default strategy used by backoff.Retry()
+  constant:<d>          always wait for <d>
+  linear:<d>            wait for <d>, 2*<d>, 3*<d>, ...
+  exponential:<d>       wait for <d>, 2*<d>, 4*<d>, ...
+  geometric:<d>         wait for <d>, f*<d>, f^2*<d>, ... (option: factor=<f>, default 2)
+  fibonacci:<d>         wait for <d>, <d>, 2*<d>, 3*<d>, 5*<d>, ...
+  polynomial:<d>        wait for <d>, 2^k*<d>, 3^k*<d>, ... (option: degree=<k>, default 2)
+  decorrelated:<d>      "decorrelated jitter" with base <d> (requires the limit option)
+  schedule:<d>/<d>/...  wait for each <d> in turn, then repeat the last
+  finite:<d>/<d>/...    wait for each <d> in turn, then stop
+
+Options:
+  jitter=<j>            apply jitter, one of none, full, equal, exponential,
+                        proportional:<p>, normal:<stddev> or lognormal:<sigma>
+  min=<d>               never wait for less than <d>
+  limit=<d>             never wait for more than <d>
+  max-attempts=<n>      stop after <n> attempts
+  seed=<n>              use a deterministic source of randomness
+
+Durations are given in Go syntax, such as 500ms, 3s or 1h30m.`
+
+// parseStrategy returns the strategy described by spec.
+//
+// See strategyHelp for a description of the syntax.
+func parseStrategy(spec string) (backoff.Strategy, error) {
+	s, err := parseStrategySpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid strategy %q: %w", spec, err)
+	}
+
+	return s, nil
+}
+
+// parseStrategySpec returns the strategy described by spec.
+func parseStrategySpec(spec string) (backoff.Strategy, error) {
+	parts := strings.Split(spec, ",")
+	kind, arg, hasArg := strings.Cut(strings.TrimSpace(parts[0]), ":")
+
+	opts, err := parseOptions(parts[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	src := linger.GlobalRandSource
+	if opts.seeded {
+		src = linger.NewSeededRandSource(opts.seed)
+	}
+
+	var s backoff.Strategy
+
+	switch kind {
+	case "default":
+		if hasArg {
+			return nil, errors.New("the default strategy does not accept an argument")
+		}
+		s = backoff.DefaultStrategyWith(src)
+
+	case "constant":
+		s, err = withUnit(arg, hasArg, backoff.Constant)
+
+	case "linear":
+		s, err = withUnit(arg, hasArg, backoff.Linear)
+
+	case "exponential":
+		s, err = withUnit(arg, hasArg, backoff.Exponential)
+
+	case "geometric":
+		s, err = withUnit(arg, hasArg, func(unit time.Duration) backoff.Strategy {
+			return backoff.Geometric(unit, opts.factor)
+		})
+
+	case "fibonacci":
+		s, err = withUnit(arg, hasArg, backoff.Fibonacci)
+
+	case "polynomial":
+		s, err = withUnit(arg, hasArg, func(unit time.Duration) backoff.Strategy {
+			return backoff.Polynomial(unit, opts.degree)
+		})
+
+	case "decorrelated":
+		if opts.limit <= 0 {
+			return nil, errors.New("the decorrelated strategy requires the limit option")
+		}
+
+		base, err := parseUnit(arg, hasArg)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.limit < base {
+			return nil, errors.New("the limit option must not be less than the base duration of the decorrelated strategy")
+		}
+
+		s = backoff.DecorrelatedJitterWith(base, opts.limit, src)()
+
+	case "schedule":
+		s, err = withSchedule(arg, hasArg, backoff.Schedule)
+
+	case "finite":
+		s, err = withSchedule(arg, hasArg, backoff.FiniteSchedule)
+
+	case "":
+		return nil, errors.New("the strategy kind is missing")
+
+	default:
+		return nil, fmt.Errorf("unrecognized strategy kind %q", kind)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.hasFactor && kind != "geometric" {
+		return nil, fmt.Errorf("the factor option does not apply to the %s strategy", kind)
+	}
+
+	if opts.hasDegree && kind != "polynomial" {
+		return nil, fmt.Errorf("the degree option does not apply to the %s strategy", kind)
+	}
+
+	if opts.jitter != "" {
+		x, err := parseJitter(opts.jitter, src)
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			s = backoff.WithTransforms(s, x)
+		}
+	}
+
+	if opts.min > 0 || opts.limit > 0 {
+		max := opts.limit
+		if max <= 0 {
+			max = linger.MaxDuration
+		}
+
+		if opts.min > max {
+			return nil, errors.New("the min option must not exceed the limit option")
+		}
+
+		s = backoff.WithTransforms(s, linger.Limiter(opts.min, max))
+	}
+
+	if opts.maxAttempts > 0 {
+		s = backoff.WithMaxAttempts(s, opts.maxAttempts)
+	}
+
+	return s, nil
+}
+
+// strategyOptions is the set of options that may follow the strategy kind.
+type strategyOptions struct {
+	jitter      string
+	min, limit  time.Duration
+	maxAttempts uint
+	factor      float64
+	hasFactor   bool
+	degree      uint
+	hasDegree   bool
+	seed        uint64
+	seeded      bool
+}
+
+// parseOptions parses the given key=value pairs.
+func parseOptions(pairs []string) (strategyOptions, error) {
+	opts := strategyOptions{
+		factor: 2,
+		degree: 2,
+	}
+
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" || value == "" {
+			return opts, fmt.Errorf("option %q is not in <option>=<value> format", pair)
+		}
+
+		var err error
+
+		switch key {
+		case "jitter":
+			opts.jitter = value
+		case "min":
+			opts.min, err = parseDuration(value)
+		case "limit":
+			opts.limit, err = parseDuration(value)
+		case "max-attempts":
+			opts.maxAttempts, err = parseUint(value)
+			if err == nil && opts.maxAttempts == 0 {
+				err = errors.New("must be positive")
+			}
+		case "factor":
+			opts.factor, err = strconv.ParseFloat(value, 64)
+			if err == nil && !(opts.factor >= 1) {
+				err = errors.New("must be at least 1")
+			}
+			opts.hasFactor = true
+		case "degree":
+			opts.degree, err = parseUint(value)
+			opts.hasDegree = true
+		case "seed":
+			opts.seed, err = strconv.ParseUint(value, 10, 64)
+			opts.seeded = true
+		default:
+			return opts, fmt.Errorf("unrecognized option %q", key)
+		}
+
+		if err != nil {
+			return opts, fmt.Errorf("invalid value for the %s option: %w", key, err)
+		}
+	}
+
+	return opts, nil
+}
+
+// parseJitter returns the transform described by spec, using src as the
+// source of randomness. It returns nil if spec is "none".
+func parseJitter(spec string, src linger.RandSource) (linger.DurationTransform, error) {
+	kind, arg, hasArg := strings.Cut(spec, ":")
+
+	param := func() (float64, error) {
+		if !hasArg {
+			return 0, fmt.Errorf("the %s jitter requires an argument, such as %s:0.2", kind, kind)
+		}
+
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil || !(v >= 0) || math.IsInf(v, 0) {
+			return 0, fmt.Errorf("invalid argument for the %s jitter: %q", kind, arg)
+		}
+
+		return v, nil
+	}
+
+	if !hasArg {
+		switch kind {
+		case "none":
+			return nil, nil
+		case "full":
+			return linger.FullJitterWith(src), nil
+		case "equal":
+			return linger.EqualJitterWith(src), nil
+		case "exponential":
+			return linger.ExponentialJitterWith(src), nil
+		}
+	}
+
+	switch kind {
+	case "proportional":
+		p, err := param()
+		if err != nil {
+			return nil, err
+		}
+		return linger.ProportionalJitterWith(p, src), nil
+
+	case "normal":
+		stddev, err := param()
+		if err != nil {
+			return nil, err
+		}
+		return linger.NormalJitterWith(stddev, src), nil
+
+	case "lognormal":
+		sigma, err := param()
+		if err != nil {
+			return nil, err
+		}
+		return linger.LogNormalJitterWith(sigma, src), nil
+	}
+
+	return nil, fmt.Errorf("unrecognized jitter %q", spec)
+}
+
+// withUnit parses arg as a duration and passes it to fn.
+func withUnit(
+	arg string,
+	hasArg bool,
+	fn func(time.Duration) backoff.Strategy,
+) (backoff.Strategy, error) {
+	d, err := parseUnit(arg, hasArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return fn(d), nil
+}
+
+// parseUnit parses arg as the positive duration argument of a strategy.
+func parseUnit(arg string, hasArg bool) (time.Duration, error) {
+	if !hasArg {
+		return 0, errors.New("a duration argument is required, such as 3s")
+	}
+
+	return parseDuration(arg)
+}
+
+// withSchedule parses arg as a slash-separated list of durations and passes
+// them to fn.
+func withSchedule(
+	arg string,
+	hasArg bool,
+	fn func(...time.Duration) backoff.Strategy,
+) (backoff.Strategy, error) {
+	if !hasArg {
+		return nil, errors.New("a list of durations is required, such as 1s/5s/30s")
+	}
+
+	var delays []time.Duration
+
+	for _, v := range strings.Split(arg, "/") {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if d < 0 {
+			return nil, fmt.Errorf("duration %q must not be negative", v)
+		}
+
+		delays = append(delays, d)
+	}
+
+	return fn(delays...), nil
+}
+
+// parseDuration parses a positive duration.
+func parseDuration(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", v)
+	}
+
+	return d, nil
+}
+
+// parseUint parses an unsigned integer.
+func parseUint(v string) (uint, error) {
+	n, err := strconv.ParseUint(v, 10, 0)
+	return uint(n), err
+}
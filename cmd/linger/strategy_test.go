@@ -0,0 +1,171 @@
+package main
+
+import (
+	"time"
+
+	"github.com/dogmatiq/linger"
+	"github.com/dogmatiq/linger/backoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable(
+	"func parseStrategy()",
+	func(spec string, expect []time.Duration) {
+		s, err := parseStrategy(spec)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(backoff.Take(s, 5)).To(Equal(expect))
+	},
+	Entry(
+		"constant",
+		"constant:3s",
+		[]time.Duration{3 * time.Second, 3 * time.Second, 3 * time.Second, 3 * time.Second, 3 * time.Second},
+	),
+	Entry(
+		"linear",
+		"linear:1s",
+		[]time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second, 5 * time.Second},
+	),
+	Entry(
+		"exponential",
+		"exponential:1s",
+		[]time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second},
+	),
+	Entry(
+		"geometric",
+		"geometric:1s,factor=3",
+		[]time.Duration{1 * time.Second, 3 * time.Second, 9 * time.Second, 27 * time.Second, 81 * time.Second},
+	),
+	Entry(
+		"fibonacci",
+		"fibonacci:1s",
+		[]time.Duration{1 * time.Second, 1 * time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second},
+	),
+	Entry(
+		"polynomial",
+		"polynomial:1s,degree=3",
+		[]time.Duration{1 * time.Second, 8 * time.Second, 27 * time.Second, 64 * time.Second, 125 * time.Second},
+	),
+	Entry(
+		"schedule",
+		"schedule:1s/5s/30s",
+		[]time.Duration{1 * time.Second, 5 * time.Second, 30 * time.Second, 30 * time.Second, 30 * time.Second},
+	),
+	Entry(
+		"finite schedule",
+		"finite:1s/5s",
+		[]time.Duration{1 * time.Second, 5 * time.Second},
+	),
+	Entry(
+		"limit",
+		"exponential:1s,limit=5s",
+		[]time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second},
+	),
+	Entry(
+		"min",
+		"linear:1s,min=3s",
+		[]time.Duration{3 * time.Second, 3 * time.Second, 3 * time.Second, 4 * time.Second, 5 * time.Second},
+	),
+	Entry(
+		"max attempts",
+		"constant:1s,max-attempts=3",
+		[]time.Duration{1 * time.Second, 1 * time.Second},
+	),
+	Entry(
+		"no jitter",
+		"constant:1s,jitter=none",
+		[]time.Duration{1 * time.Second, 1 * time.Second, 1 * time.Second, 1 * time.Second, 1 * time.Second},
+	),
+	Entry(
+		"whitespace",
+		" linear:1s , limit=2s",
+		[]time.Duration{1 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second},
+	),
+)
+
+var _ = Describe("func parseStrategy()", func() {
+	It("produces the same delays as DefaultStrategy for the default kind", func() {
+		s, err := parseStrategy("default,seed=123")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		expect := backoff.DefaultStrategyWith(linger.NewSeededRandSource(123))
+		Expect(backoff.Take(s, 10)).To(Equal(backoff.Take(expect, 10)))
+	})
+
+	DescribeTable(
+		"it applies jitter",
+		func(spec string, min, max time.Duration) {
+			s, err := parseStrategy(spec)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			for _, d := range backoff.Take(s, 100) {
+				Expect(d).To(BeNumerically(">=", min))
+				Expect(d).To(BeNumerically("<=", max))
+			}
+		},
+		Entry("full", "constant:10s,jitter=full", time.Duration(0), 10*time.Second),
+		Entry("equal", "constant:10s,jitter=equal", 5*time.Second, 10*time.Second),
+		Entry("proportional", "constant:10s,jitter=proportional:0.5", 10*time.Second, 15*time.Second),
+		Entry("exponential", "constant:10s,jitter=exponential,limit=1m", time.Duration(0), time.Minute),
+		Entry("normal", "constant:10s,jitter=normal:0.1,limit=1m", time.Duration(0), time.Minute),
+		Entry("lognormal", "constant:10s,jitter=lognormal:0.1,limit=1m", time.Duration(0), time.Minute),
+		Entry("decorrelated", "decorrelated:1s,limit=10s", 1*time.Second, 10*time.Second),
+	)
+
+	It("applies the limit after the jitter", func() {
+		s, err := parseStrategy("constant:10s,jitter=proportional:1,limit=12s")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		for _, d := range backoff.Take(s, 100) {
+			Expect(d).To(BeNumerically("<=", 12*time.Second))
+		}
+	})
+
+	It("produces the same delays for the same seed", func() {
+		a, err := parseStrategy("exponential:1s,jitter=full,seed=42")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		b, err := parseStrategy("exponential:1s,jitter=full,seed=42")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(backoff.Take(a, 10)).To(Equal(backoff.Take(b, 10)))
+	})
+
+	DescribeTable(
+		"it returns an error if the spec is invalid",
+		func(spec, expect string) {
+			_, err := parseStrategy(spec)
+			Expect(err).To(MatchError(ContainSubstring(expect)))
+		},
+		Entry("empty", "", "the strategy kind is missing"),
+		Entry("unknown kind", "quadratic:1s", `unrecognized strategy kind "quadratic"`),
+		Entry("missing argument", "exponential", "a duration argument is required"),
+		Entry("invalid duration", "exponential:3x", "unknown unit"),
+		Entry("non-positive duration", "exponential:0s", "must be positive"),
+		Entry("argument to default", "default:1s", "does not accept an argument"),
+		Entry("missing schedule", "schedule", "a list of durations is required"),
+		Entry("invalid schedule", "schedule:1s/x", "invalid duration"),
+		Entry("negative schedule", "schedule:1s/-1s", "must not be negative"),
+		Entry("malformed option", "exponential:1s,limit", "not in <option>=<value> format"),
+		Entry("unknown option", "exponential:1s,color=red", `unrecognized option "color"`),
+		Entry("invalid limit", "exponential:1s,limit=soon", "invalid value for the limit option"),
+		Entry("zero max attempts", "exponential:1s,max-attempts=0", "must be positive"),
+		Entry("small factor", "geometric:1s,factor=0.5", "must be at least 1"),
+		Entry("unknown jitter", "exponential:1s,jitter=wobbly", `unrecognized jitter "wobbly"`),
+		Entry("missing jitter argument", "exponential:1s,jitter=normal", "requires an argument"),
+		Entry("invalid jitter argument", "exponential:1s,jitter=normal:-1", "invalid argument"),
+		Entry("min exceeds limit", "exponential:1s,min=1m,limit=1s", "must not exceed the limit"),
+		Entry("decorrelated without limit", "decorrelated:1s", "requires the limit option"),
+		Entry("decorrelated limit below base", "decorrelated:10s,limit=5s", "must not be less than the base duration"),
+		Entry("NaN jitter argument", "exponential:1s,jitter=normal:NaN", "invalid argument"),
+		Entry("infinite jitter argument", "exponential:1s,jitter=lognormal:Inf", "invalid argument"),
+		Entry("factor for another kind", "constant:1s,factor=3", "the factor option does not apply to the constant strategy"),
+		Entry("degree for another kind", "exponential:1s,degree=9", "the degree option does not apply to the exponential strategy"),
+	)
+
+	It("includes the spec in the error message", func() {
+		_, err := parseStrategy("quadratic:1s")
+		Expect(err).To(MatchError(`invalid strategy "quadratic:1s": unrecognized strategy kind "quadratic"`))
+	})
+})
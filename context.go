@@ -2,6 +2,7 @@ package linger
 
 import (
 	"context"
+	"sync"
 	"time"
 )
 
@@ -10,8 +11,17 @@ import (
 //
 // ok is false if ctx does not have a deadline.
 func FromContextDeadline(ctx context.Context) (d time.Duration, ok bool) {
+	return FromContextDeadlineC(ctx, nil)
+}
+
+// FromContextDeadlineC returns the duration until the deadline of ctx is
+// reached, as measured by the clock c.
+//
+// It behaves like FromContextDeadline(), except that it uses c to measure time.
+// If c is nil, the clock carried by ctx is used, as per ClockFromContext().
+func FromContextDeadlineC(ctx context.Context, c Clock) (d time.Duration, ok bool) {
 	if dl, ok := ctx.Deadline(); ok {
-		return time.Until(dl), true
+		return clockOrDefault(ctx, c).Until(dl), true
 	}
 
 	return 0, false
@@ -44,7 +54,133 @@ func ContextWithTimeoutX(
 	ctx context.Context,
 	x DurationTransform,
 	durations ...time.Duration,
+) (context.Context, func()) {
+	return ContextWithTimeoutXC(ctx, nil, x, durations...)
+}
+
+// ContextWithTimeoutC returns a context with a deadline some duration after the
+// current time, as measured by the clock c.
+//
+// It behaves like ContextWithTimeout(), except that it uses c to measure time.
+// If c is nil, the clock carried by ctx is used, as per ClockFromContext().
+func ContextWithTimeoutC(
+	ctx context.Context,
+	c Clock,
+	durations ...time.Duration,
+) (context.Context, func()) {
+	return ContextWithTimeoutXC(ctx, c, Identity, durations...)
+}
+
+// ContextWithTimeoutXC returns a context with a deadline some duration after
+// the current time, as measured by the clock c.
+//
+// It behaves like ContextWithTimeoutX(), except that it uses c to measure time.
+// If c is nil, the clock carried by ctx is used, as per ClockFromContext().
+func ContextWithTimeoutXC(
+	ctx context.Context,
+	c Clock,
+	x DurationTransform,
+	durations ...time.Duration,
 ) (context.Context, func()) {
 	d, _ := Coalesce(durations...)
-	return context.WithTimeout(ctx, x(d))
+	d = x(d)
+
+	c = clockOrDefault(ctx, c)
+	if c == SystemClock {
+		return context.WithTimeout(ctx, d)
+	}
+
+	return withClockTimeout(ctx, c, d)
+}
+
+// withClockTimeout returns a context that is canceled when d has elapsed, as
+// measured by the clock c.
+//
+// As per context.WithDeadline(), if the parent's deadline is already earlier
+// than the new deadline, the returned context simply inherits the parent's
+// deadline.
+func withClockTimeout(
+	parent context.Context,
+	c Clock,
+	d time.Duration,
+) (context.Context, func()) {
+	ctx := &clockContext{
+		Context:  parent,
+		deadline: c.Now().Add(d),
+		done:     make(chan struct{}),
+	}
+	cancel := func() { ctx.cancel(context.Canceled) }
+
+	if pd, ok := parent.Deadline(); ok && pd.Before(ctx.deadline) {
+		ctx.deadline = pd
+		go ctx.wait(parent, nil)
+		return ctx, cancel
+	}
+
+	if d <= 0 {
+		ctx.cancel(context.DeadlineExceeded)
+		return ctx, cancel
+	}
+
+	t := c.NewTimer(d)
+	go func() {
+		defer t.Stop()
+		ctx.wait(parent, t.C())
+	}()
+
+	return ctx, cancel
+}
+
+// clockContext is a context with a deadline that is measured by a Clock other
+// than the system clock.
+//
+// It has its own done channel, rather than using that of a context created by
+// context.WithCancel(). This causes the standard library to propagate
+// cancelation to child contexts via Done() and Err(), such that they report
+// context.DeadlineExceeded when the deadline is reached.
+type clockContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	once sync.Once
+	m    sync.Mutex
+	err  error
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *clockContext) Err() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.err
+}
+
+// wait blocks until c is canceled, cancelling it if the parent is canceled or
+// a value is received from expired.
+func (c *clockContext) wait(parent context.Context, expired <-chan time.Time) {
+	select {
+	case <-c.done:
+	case <-parent.Done():
+		c.cancel(parent.Err())
+	case <-expired:
+		c.cancel(context.DeadlineExceeded)
+	}
+}
+
+// cancel cancels c with the given error, if it has not already been canceled.
+func (c *clockContext) cancel(err error) {
+	c.once.Do(func() {
+		c.m.Lock()
+		c.err = err
+		c.m.Unlock()
+
+		close(c.done)
+	})
 }
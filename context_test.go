@@ -5,6 +5,7 @@ import (
 	"time"
 
 	. "github.com/dogmatiq/linger"
+	"github.com/dogmatiq/linger/lingertest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -60,3 +61,150 @@ var _ = Describe("func ContextWithTimeoutX()", func() {
 		Expect(dl).To(BeTemporally("~", expect))
 	})
 })
+
+var _ = Describe("func FromContextDeadlineC()", func() {
+	It("returns the time until the deadline of the context", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		d, ok := FromContextDeadlineC(ctx, wrappedClock{SystemClock})
+		Expect(ok).To(BeTrue())
+		Expect(d).To(BeNumerically("~", 10*time.Second, 1*time.Second))
+	})
+})
+
+var _ = Describe("func ContextWithTimeoutXC()", func() {
+	It("sets a deadline based on the given clock", func() {
+		expect := time.Now().Add(10 * time.Second)
+		ctx, cancel := ContextWithTimeoutXC(context.Background(), wrappedClock{SystemClock}, Identity, 10*time.Second)
+		defer cancel()
+
+		dl, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(dl).To(BeTemporally("~", expect))
+	})
+
+	It("cancels the context with a deadline error when the timer fires", func() {
+		ctx, cancel := ContextWithTimeoutXC(context.Background(), wrappedClock{SystemClock}, Identity, 10*time.Millisecond)
+		defer cancel()
+
+		<-ctx.Done()
+		Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("times out 'immediately' if none of the durations are positive", func() {
+		ctx, cancel := ContextWithTimeoutXC(context.Background(), wrappedClock{SystemClock}, Identity, 0)
+		defer cancel()
+
+		Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("returns a canceled error if the cancel function is called", func() {
+		ctx, cancel := ContextWithTimeoutXC(context.Background(), wrappedClock{SystemClock}, Identity, 10*time.Second)
+		cancel()
+
+		Expect(ctx.Err()).To(Equal(context.Canceled))
+	})
+})
+
+var _ = Describe("func ContextWithTimeout() (with a fake clock)", func() {
+	It("times out when the clock carried by the context reaches the deadline", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		parent := clock.WithContext(context.Background())
+
+		ctx, cancel := ContextWithTimeout(parent, 1*time.Hour)
+		defer cancel()
+
+		dl, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(dl).To(Equal(clock.Now().Add(1 * time.Hour)))
+
+		d, ok := FromContextDeadline(ctx)
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(1 * time.Hour))
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(1 * time.Hour)
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("reports a deadline error from contexts derived from it", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		parent := clock.WithContext(context.Background())
+
+		ctx, cancel := ContextWithTimeout(parent, 1*time.Hour)
+		defer cancel()
+
+		child, cancelChild := context.WithCancel(ctx)
+		defer cancelChild()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(1 * time.Hour)
+
+		Eventually(child.Done()).Should(BeClosed())
+		Expect(child.Err()).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("cancels contexts derived from it when the cancel function is called", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		parent := clock.WithContext(context.Background())
+
+		ctx, cancel := ContextWithTimeout(parent, 1*time.Hour)
+		child, cancelChild := context.WithCancel(ctx)
+		defer cancelChild()
+
+		cancel()
+
+		Eventually(child.Done()).Should(BeClosed())
+		Expect(child.Err()).To(Equal(context.Canceled))
+	})
+
+	It("inherits the parent's deadline if it is earlier", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+
+		parent, cancelParent := ContextWithTimeout(clock.WithContext(context.Background()), 10*time.Second)
+		defer cancelParent()
+
+		ctx, cancel := ContextWithTimeout(parent, 1*time.Hour)
+		defer cancel()
+
+		dl, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(dl).To(Equal(clock.Now().Add(10 * time.Second)))
+
+		d, ok := FromContextDeadline(ctx)
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(10 * time.Second))
+
+		// Only the parent's timer is waiting.
+		clock.BlockUntilWaiters(1)
+		Expect(clock.Waiters()).To(Equal(1))
+		clock.Advance(10 * time.Second)
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("uses its own deadline if it is earlier than the parent's", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+
+		parent, cancelParent := ContextWithTimeout(clock.WithContext(context.Background()), 1*time.Hour)
+		defer cancelParent()
+
+		ctx, cancel := ContextWithTimeout(parent, 10*time.Second)
+		defer cancel()
+
+		dl, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(dl).To(Equal(clock.Now().Add(10 * time.Second)))
+
+		clock.BlockUntilWaiters(2)
+		clock.Advance(10 * time.Second)
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+		Expect(parent.Err()).ShouldNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,120 @@
+package linger
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// NormalJitter returns a DurationTransform that applies jitter drawn from a
+// truncated normal distribution.
+//
+// The output duration is drawn from a normal distribution with a mean equal to
+// the input duration d, and a standard deviation of d*stddev. The distribution
+// is truncated such that the output is never negative, and saturates at
+// MaxDuration. It returns 0 if d is not positive.
+//
+// It panics if stddev is negative or NaN.
+func NormalJitter(stddev float64) DurationTransform {
+	return NormalJitterWith(stddev, GlobalRandSource)
+}
+
+// NormalJitterWith returns a DurationTransform that applies jitter drawn from a
+// truncated normal distribution, using src as the source of randomness.
+//
+// It behaves like NormalJitter(), except that random values are obtained from
+// src.
+func NormalJitterWith(stddev float64, src RandSource) DurationTransform {
+	if !(stddev >= 0) {
+		panic("the standard deviation must not be negative or NaN")
+	}
+
+	return func(d time.Duration) time.Duration {
+		if d <= 0 {
+			return 0
+		}
+
+		r := rand.New(src)
+		mean := float64(d)
+
+		for {
+			nanos := mean + r.NormFloat64()*mean*stddev
+
+			// Truncate the distribution by discarding negative values. At
+			// most half of the distribution is negative, so this terminates
+			// quickly.
+			if nanos >= 0 {
+				return fromNanosSaturating(nanos)
+			}
+		}
+	}
+}
+
+// ExponentialJitter is a DurationTransform that applies jitter drawn from an
+// exponential distribution.
+//
+// The output duration is drawn from an exponential distribution with a mean
+// equal to the input duration. It saturates at MaxDuration. It returns 0 if
+// the input duration is not positive.
+func ExponentialJitter(d time.Duration) time.Duration {
+	return exponentialJitter(GlobalRandSource, d)
+}
+
+// ExponentialJitterWith returns a DurationTransform that applies jitter drawn
+// from an exponential distribution, using src as the source of randomness.
+//
+// It behaves like ExponentialJitter(), except that random values are obtained
+// from src.
+func ExponentialJitterWith(src RandSource) DurationTransform {
+	return func(d time.Duration) time.Duration {
+		return exponentialJitter(src, d)
+	}
+}
+
+// exponentialJitter returns a duration drawn from an exponential distribution
+// with a mean of d.
+func exponentialJitter(src RandSource, d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	return fromNanosSaturating(
+		rand.New(src).ExpFloat64() * float64(d),
+	)
+}
+
+// LogNormalJitter returns a DurationTransform that applies jitter drawn from a
+// log-normal distribution.
+//
+// The output duration is d*e^X, where d is the input duration and X is drawn
+// from a normal distribution with a mean of 0 and a standard deviation of
+// sigma. Hence, the median output duration is equal to d. It saturates at
+// MaxDuration. It returns 0 if d is not positive.
+//
+// It panics if sigma is negative or NaN.
+func LogNormalJitter(sigma float64) DurationTransform {
+	return LogNormalJitterWith(sigma, GlobalRandSource)
+}
+
+// LogNormalJitterWith returns a DurationTransform that applies jitter drawn
+// from a log-normal distribution, using src as the source of randomness.
+//
+// It behaves like LogNormalJitter(), except that random values are obtained
+// from src.
+func LogNormalJitterWith(sigma float64, src RandSource) DurationTransform {
+	if !(sigma >= 0) {
+		panic("the standard deviation must not be negative or NaN")
+	}
+
+	return func(d time.Duration) time.Duration {
+		if d <= 0 {
+			return 0
+		}
+
+		x := rand.New(src).NormFloat64() * sigma
+
+		return fromNanosSaturating(
+			float64(d) * math.Exp(x),
+		)
+	}
+}
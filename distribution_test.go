@@ -0,0 +1,188 @@
+package linger_test
+
+import (
+	"math"
+	"time"
+
+	. "github.com/dogmatiq/linger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// mean returns the mean of n durations produced by x with an input of d.
+func mean(x DurationTransform, d time.Duration, n int) time.Duration {
+	var total float64
+	for i := 0; i < n; i++ {
+		total += float64(x(d))
+	}
+	return time.Duration(total / float64(n))
+}
+
+var _ = Describe("func NormalJitter()", func() {
+	It("returns values centered around d", func() {
+		m := mean(NormalJitter(0.1), 100*time.Second, 10000)
+		Expect(m).To(BeNumerically("~", 100*time.Second, 2*time.Second))
+	})
+
+	It("never returns a negative value", func() {
+		x := NormalJitter(10)
+		for i := 0; i < 1000; i++ {
+			Expect(x(1 * time.Second)).To(BeNumerically(">=", 0))
+		}
+	})
+
+	It("saturates at MaxDuration", func() {
+		// With such a small standard deviation, roughly half of the values
+		// drawn for MaxDuration exceed it, and must saturate.
+		x := NormalJitterWith(1e-12, NewSeededRandSource(123))
+		saturated := 0
+
+		for i := 0; i < 100; i++ {
+			d := x(MaxDuration)
+			Expect(d).To(BeNumerically("~", MaxDuration, 1*time.Second))
+
+			if d == MaxDuration {
+				saturated++
+			}
+		}
+
+		Expect(saturated).To(BeNumerically("~", 50, 20))
+	})
+
+	It("returns zero if d is not positive", func() {
+		Expect(NormalJitter(0.1)(-1 * time.Second)).To(Equal(time.Duration(0)))
+	})
+
+	It("returns d if the standard deviation is zero", func() {
+		Expect(NormalJitter(0)(1 * time.Second)).To(Equal(1 * time.Second))
+	})
+
+	It("panics if the standard deviation is negative", func() {
+		Expect(func() {
+			NormalJitter(-1)
+		}).To(Panic())
+	})
+
+	It("panics if the standard deviation is NaN", func() {
+		Expect(func() {
+			NormalJitter(math.NaN())
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("func NormalJitterWith()", func() {
+	It("produces the same values for the same seed", func() {
+		a := NormalJitterWith(0.5, NewSeededRandSource(123))
+		b := NormalJitterWith(0.5, NewSeededRandSource(123))
+
+		for i := 0; i < 10; i++ {
+			Expect(a(100 * time.Second)).To(Equal(b(100 * time.Second)))
+		}
+	})
+})
+
+var _ = Describe("func ExponentialJitter()", func() {
+	It("returns values with a mean of d", func() {
+		m := mean(ExponentialJitter, 100*time.Second, 10000)
+		Expect(m).To(BeNumerically("~", 100*time.Second, 5*time.Second))
+	})
+
+	It("never returns a negative value", func() {
+		for i := 0; i < 1000; i++ {
+			Expect(ExponentialJitter(1 * time.Second)).To(BeNumerically(">=", 0))
+		}
+	})
+
+	It("saturates at MaxDuration", func() {
+		// Roughly 1/e of the values drawn from an exponential distribution
+		// exceed the mean, and must saturate.
+		x := ExponentialJitterWith(NewSeededRandSource(123))
+		saturated := 0
+
+		for i := 0; i < 1000; i++ {
+			d := x(MaxDuration)
+			Expect(d).To(BeNumerically(">=", 0))
+
+			if d == MaxDuration {
+				saturated++
+			}
+		}
+
+		Expect(saturated).To(BeNumerically("~", 368, 60))
+	})
+
+	It("returns zero if d is not positive", func() {
+		Expect(ExponentialJitter(0)).To(Equal(time.Duration(0)))
+	})
+})
+
+var _ = Describe("func ExponentialJitterWith()", func() {
+	It("produces the same values for the same seed", func() {
+		a := ExponentialJitterWith(NewSeededRandSource(123))
+		b := ExponentialJitterWith(NewSeededRandSource(123))
+
+		for i := 0; i < 10; i++ {
+			Expect(a(100 * time.Second)).To(Equal(b(100 * time.Second)))
+		}
+	})
+})
+
+var _ = Describe("func LogNormalJitter()", func() {
+	It("returns values with a median of d", func() {
+		x := LogNormalJitter(0.5)
+		below := 0
+
+		for i := 0; i < 10000; i++ {
+			if x(100*time.Second) < 100*time.Second {
+				below++
+			}
+		}
+
+		Expect(below).To(BeNumerically("~", 5000, 300))
+	})
+
+	It("saturates at MaxDuration", func() {
+		// With such a small sigma, roughly half of the values drawn for
+		// MaxDuration exceed it, and must saturate.
+		x := LogNormalJitterWith(1e-12, NewSeededRandSource(123))
+		saturated := 0
+
+		for i := 0; i < 100; i++ {
+			d := x(MaxDuration)
+			Expect(d).To(BeNumerically("~", MaxDuration, 1*time.Second))
+
+			if d == MaxDuration {
+				saturated++
+			}
+		}
+
+		Expect(saturated).To(BeNumerically("~", 50, 20))
+	})
+
+	It("returns zero if d is not positive", func() {
+		Expect(LogNormalJitter(0.5)(0)).To(Equal(time.Duration(0)))
+	})
+
+	It("panics if sigma is negative", func() {
+		Expect(func() {
+			LogNormalJitter(-1)
+		}).To(Panic())
+	})
+
+	It("panics if sigma is NaN", func() {
+		Expect(func() {
+			LogNormalJitter(math.NaN())
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("func LogNormalJitterWith()", func() {
+	It("produces the same values for the same seed", func() {
+		a := LogNormalJitterWith(0.5, NewSeededRandSource(123))
+		b := LogNormalJitterWith(0.5, NewSeededRandSource(123))
+
+		for i := 0; i < 10; i++ {
+			Expect(a(100 * time.Second)).To(Equal(b(100 * time.Second)))
+		}
+	})
+})
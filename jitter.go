@@ -2,7 +2,7 @@ package linger
 
 import (
 	"math"
-	"math/rand"
+	"math/rand/v2"
 	"time"
 )
 
@@ -15,6 +15,17 @@ func FullJitter(d time.Duration) time.Duration {
 	return Rand(0, d)
 }
 
+// FullJitterWith returns a DurationTransform that applies "full jitter" to the
+// input duration, using src as the source of randomness.
+//
+// It behaves like FullJitter(), except that random values are obtained from
+// src.
+func FullJitterWith(src RandSource) DurationTransform {
+	return func(d time.Duration) time.Duration {
+		return RandWith(src, 0, d)
+	}
+}
+
 // ProportionalJitter returns a DurationTransform that applies "proportional
 // jitter" to the input duration.
 //
@@ -26,37 +37,125 @@ func FullJitter(d time.Duration) time.Duration {
 // 10% to the input duration. p may be negative to indicate that the jitter
 // amount should be subtracted from the input duration.
 func ProportionalJitter(p float64) DurationTransform {
+	return ProportionalJitterWith(p, GlobalRandSource)
+}
+
+// ProportionalJitterWith returns a DurationTransform that applies
+// "proportional jitter" to the input duration, using src as the source of
+// randomness.
+//
+// It behaves like ProportionalJitter(), except that random values are obtained
+// from src.
+func ProportionalJitterWith(p float64, src RandSource) DurationTransform {
 	return func(d time.Duration) time.Duration {
 		j := Multiply(d, p)
-		return Rand(d, d+j)
+		return RandWith(src, d, d+j)
+	}
+}
+
+// EqualJitter is a DurationTransform that applies "equal jitter" to the input
+// duration.
+//
+// The output duration is half of the input duration, plus a random duration
+// between 0 and half of the input duration, inclusive.
+func EqualJitter(d time.Duration) time.Duration {
+	return Rand(d/2, d)
+}
+
+// EqualJitterWith returns a DurationTransform that applies "equal jitter" to
+// the input duration, using src as the source of randomness.
+//
+// It behaves like EqualJitter(), except that random values are obtained from
+// src.
+func EqualJitterWith(src RandSource) DurationTransform {
+	return func(d time.Duration) time.Duration {
+		return RandWith(src, d/2, d)
+	}
+}
+
+// BoundedJitter returns a DurationTransform that applies "bounded jitter" to
+// the input duration.
+//
+// The output duration is a random duration between d*min and d*max, inclusive,
+// where d is the input duration. For example, BoundedJitter(0.5, 1) is
+// equivalent to EqualJitter(). The bounds saturate at MinDuration and
+// MaxDuration.
+func BoundedJitter(min, max float64) DurationTransform {
+	return BoundedJitterWith(min, max, GlobalRandSource)
+}
+
+// BoundedJitterWith returns a DurationTransform that applies "bounded jitter"
+// to the input duration, using src as the source of randomness.
+//
+// It behaves like BoundedJitter(), except that random values are obtained from
+// src.
+func BoundedJitterWith(min, max float64, src RandSource) DurationTransform {
+	return func(d time.Duration) time.Duration {
+		return RandWith(
+			src,
+			multiplySaturating(d, min),
+			multiplySaturating(d, max),
+		)
 	}
 }
 
 // Rand returns a random duration between a and b, inclusive.
 func Rand(a, b time.Duration) time.Duration {
+	return RandWith(GlobalRandSource, a, b)
+}
+
+// RandWith returns a random duration between a and b, inclusive, using src as
+// the source of randomness.
+func RandWith(src RandSource, a, b time.Duration) time.Duration {
 	if b < a {
 		a, b = b, a
 	}
 
-	diff := int64(b - a)
+	// The difference is computed as an unsigned integer so that it can
+	// represent the full range between MinDuration and MaxDuration.
+	diff := uint64(b) - uint64(a)
+
+	if diff == math.MaxUint64 {
+		return time.Duration(src.Uint64())
+	}
 
 	return a + time.Duration(
-		inclusiveRand(diff),
+		uint64N(src, diff+1),
 	)
 }
 
-// inclusiveRand returns a positive unsigned integer less than or equal to n.
-func inclusiveRand(n int64) int64 {
-	if n == 0 {
-		return 0
+// uint64N returns a random unsigned integer in the half-open range [0, n),
+// using src as the source of randomness.
+func uint64N(src RandSource, n uint64) uint64 {
+	if src == GlobalRandSource {
+		return rand.Uint64N(n)
 	}
 
-	// If n is equal to the maximum value of an int64 we can't use rand.Int63n()
-	// because it uses a half-open range. As a work-around, we produce a random
-	// unsigned integer and discard the sign bit.
-	if n == math.MaxInt64 {
-		return int64(rand.Uint64() & math.MaxInt64)
-	}
+	return rand.New(src).Uint64N(n)
+}
+
+// CryptoFullJitter is a DurationTransform that applies "full jitter" to the
+// input duration using a cryptographically secure source of randomness.
+//
+// It behaves like FullJitter(), but it should be used when the timing of
+// retries must not be predictable, such as when an attacker could otherwise
+// time their requests against those of the client.
+func CryptoFullJitter(d time.Duration) time.Duration {
+	return CryptoRand(0, d)
+}
+
+// CryptoProportionalJitter returns a DurationTransform that applies
+// "proportional jitter" to the input duration using a cryptographically secure
+// source of randomness.
+//
+// It behaves like ProportionalJitter(), but it should be used when the timing
+// of retries must not be predictable.
+func CryptoProportionalJitter(p float64) DurationTransform {
+	return ProportionalJitterWith(p, CryptoRandSource)
+}
 
-	return rand.Int63n(n + 1)
+// CryptoRand returns a random duration between a and b, inclusive, using a
+// cryptographically secure source of randomness.
+func CryptoRand(a, b time.Duration) time.Duration {
+	return RandWith(CryptoRandSource, a, b)
 }
@@ -62,3 +62,189 @@ var _ = Describe("func Rand()", func() {
 		Expect(d).To(Equal(MaxDuration))
 	})
 })
+
+var _ = Describe("func FullJitterWith()", func() {
+	It("returns a value between 0 and d", func() {
+		d := FullJitterWith(GlobalRandSource)(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 0*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+
+	It("produces the same values for the same seed", func() {
+		a := FullJitterWith(NewSeededRandSource(123))
+		b := FullJitterWith(NewSeededRandSource(123))
+
+		for i := 0; i < 10; i++ {
+			Expect(a(100 * time.Second)).To(Equal(b(100 * time.Second)))
+		}
+	})
+})
+
+var _ = Describe("func ProportionalJitterWith()", func() {
+	It("adds to the input duration when the proportion is positive", func() {
+		d := ProportionalJitterWith(0.25, GlobalRandSource)(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 100*time.Second))
+		Expect(d).To(BeNumerically("<=", 125*time.Second))
+	})
+
+	It("subtracts from the input duration when the proportion is negative", func() {
+		d := ProportionalJitterWith(-0.25, GlobalRandSource)(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 75*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+
+	It("produces the same values for the same seed", func() {
+		a := ProportionalJitterWith(0.25, NewSeededRandSource(123))
+		b := ProportionalJitterWith(0.25, NewSeededRandSource(123))
+
+		for i := 0; i < 10; i++ {
+			Expect(a(100 * time.Second)).To(Equal(b(100 * time.Second)))
+		}
+	})
+})
+
+var _ = Describe("func RandWith()", func() {
+	It("returns a value between the given values", func() {
+		d := RandWith(GlobalRandSource, 1*time.Second, 100*time.Second)
+		Expect(d).To(BeNumerically(">=", 1*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+
+	It("does not require the arguments in any particular order", func() {
+		d := RandWith(GlobalRandSource, 100*time.Second, 1*time.Second)
+		Expect(d).To(BeNumerically(">=", 1*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+
+	It("returns the value when the arguments are equal", func() {
+		d := RandWith(GlobalRandSource, 100*time.Second, 100*time.Second)
+		Expect(d).To(Equal(100 * time.Second))
+	})
+
+	It("supports the full range of durations", func() {
+		d := RandWith(GlobalRandSource, 0, MaxDuration)
+		Expect(d).To(BeNumerically(">=", 0))
+		Expect(d).To(BeNumerically("<=", MaxDuration))
+
+		d = RandWith(GlobalRandSource, MinDuration, 0)
+		Expect(d).To(BeNumerically(">=", MinDuration))
+		Expect(d).To(BeNumerically("<=", 0))
+
+		RandWith(GlobalRandSource, MinDuration, MaxDuration)
+
+		d = RandWith(GlobalRandSource, MaxDuration, MaxDuration)
+		Expect(d).To(Equal(MaxDuration))
+	})
+})
+
+var _ = Describe("func CryptoFullJitter()", func() {
+	It("returns a value between 0 and d", func() {
+		d := CryptoFullJitter(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 0*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+})
+
+var _ = Describe("func CryptoProportionalJitter()", func() {
+	It("adds to the input duration when the proportion is positive", func() {
+		d := CryptoProportionalJitter(0.25)(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 100*time.Second))
+		Expect(d).To(BeNumerically("<=", 125*time.Second))
+	})
+
+	It("subtracts from the input duration when the proportion is negative", func() {
+		d := CryptoProportionalJitter(-0.25)(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 75*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+})
+
+var _ = Describe("func CryptoRand()", func() {
+	It("returns a value between the given values", func() {
+		d := CryptoRand(1*time.Second, 100*time.Second)
+		Expect(d).To(BeNumerically(">=", 1*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+
+	It("does not require the arguments in any particular order", func() {
+		d := CryptoRand(100*time.Second, 1*time.Second)
+		Expect(d).To(BeNumerically(">=", 1*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+
+	It("supports MaxDuration", func() {
+		d := CryptoRand(0, MaxDuration)
+		Expect(d).To(BeNumerically(">=", 0))
+		Expect(d).To(BeNumerically("<=", MaxDuration))
+
+		d = CryptoRand(MaxDuration, MaxDuration)
+		Expect(d).To(Equal(MaxDuration))
+	})
+})
+
+var _ = Describe("func EqualJitter()", func() {
+	It("returns a value between d/2 and d", func() {
+		d := EqualJitter(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 50*time.Second))
+		Expect(d).To(BeNumerically("<=", 100*time.Second))
+	})
+
+	It("supports MaxDuration", func() {
+		d := EqualJitter(MaxDuration)
+		Expect(d).To(BeNumerically(">=", MaxDuration/2))
+		Expect(d).To(BeNumerically("<=", MaxDuration))
+	})
+})
+
+var _ = Describe("func EqualJitterWith()", func() {
+	It("produces the same values for the same seed", func() {
+		a := EqualJitterWith(NewSeededRandSource(123))
+		b := EqualJitterWith(NewSeededRandSource(123))
+
+		for i := 0; i < 10; i++ {
+			d := a(100 * time.Second)
+			Expect(d).To(Equal(b(100 * time.Second)))
+			Expect(d).To(BeNumerically(">=", 50*time.Second))
+			Expect(d).To(BeNumerically("<=", 100*time.Second))
+		}
+	})
+})
+
+var _ = Describe("func BoundedJitter()", func() {
+	It("returns a value between d*min and d*max", func() {
+		d := BoundedJitter(0.8, 1.5)(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 80*time.Second))
+		Expect(d).To(BeNumerically("<=", 150*time.Second))
+	})
+
+	It("does not require the bounds in any particular order", func() {
+		d := BoundedJitter(1.5, 0.8)(100 * time.Second)
+		Expect(d).To(BeNumerically(">=", 80*time.Second))
+		Expect(d).To(BeNumerically("<=", 150*time.Second))
+	})
+
+	It("returns d*min when the bounds are equal", func() {
+		d := BoundedJitter(0.5, 0.5)(100 * time.Second)
+		Expect(d).To(Equal(50 * time.Second))
+	})
+
+	It("saturates at MaxDuration", func() {
+		d := BoundedJitter(2, 3)(MaxDuration)
+		Expect(d).To(Equal(MaxDuration))
+
+		d = BoundedJitter(0.5, 3)(MaxDuration)
+		Expect(d).To(BeNumerically(">=", MaxDuration/2))
+		Expect(d).To(BeNumerically("<=", MaxDuration))
+	})
+})
+
+var _ = Describe("func BoundedJitterWith()", func() {
+	It("produces the same values for the same seed", func() {
+		a := BoundedJitterWith(0.8, 1.5, NewSeededRandSource(123))
+		b := BoundedJitterWith(0.8, 1.5, NewSeededRandSource(123))
+
+		for i := 0; i < 10; i++ {
+			Expect(a(100 * time.Second)).To(Equal(b(100 * time.Second)))
+		}
+	})
+})
@@ -0,0 +1,217 @@
+package lingertest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dogmatiq/linger"
+)
+
+// FakeClock is an implementation of linger.Clock that only advances when
+// instructed to do so.
+//
+// It can be passed directly to the clock-aware functions in the linger and
+// backoff packages, or attached to a context using WithContext() so that it
+// is used by Sleep(), SleepUntil(), backoff.Retry(), etc.
+type FakeClock struct {
+	m       sync.Mutex
+	now     time.Time
+	seq     uint64
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+// NewFakeClock returns a new fake clock with its current time set to t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// WithContext returns a child of ctx that carries the clock c.
+//
+// It is a convenience for linger.ContextWithClock(ctx, c).
+func (c *FakeClock) WithContext(ctx context.Context) context.Context {
+	return linger.ContextWithClock(ctx, c)
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.now
+}
+
+// Until returns the duration until t, according to the clock's current time.
+func (c *FakeClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// NewTimer returns a timer that fires when the clock's current time reaches
+// d after the current time.
+//
+// If d is not positive the timer fires immediately.
+func (c *FakeClock) NewTimer(d time.Duration) linger.Timer {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.seq++
+	t := &fakeTimer{
+		clock:    c,
+		seq:      c.seq,
+		deadline: c.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+	c.notify()
+
+	return t
+}
+
+// Advance moves the clock's current time forward by d, firing any timers that
+// become due.
+//
+// Timers are fired in order of their deadline. The clock's current time is
+// set to each timer's deadline as it is fired.
+func (c *FakeClock) Advance(d time.Duration) {
+	if d < 0 {
+		panic("the duration must not be negative")
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.advanceTo(c.now.Add(d))
+}
+
+// Set sets the clock's current time to t, firing any timers that become due.
+//
+// It panics if t is before the clock's current time.
+func (c *FakeClock) Set(t time.Time) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if t.Before(c.now) {
+		panic("the clock can not be moved backwards")
+	}
+
+	c.advanceTo(t)
+}
+
+// Waiters returns the number of timers that have not yet fired or been
+// stopped.
+func (c *FakeClock) Waiters() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return len(c.timers)
+}
+
+// BlockUntilWaiters blocks until there are at least n timers that have not yet
+// fired or been stopped.
+//
+// It is typically called before Advance() to ensure that the code under test
+// has started sleeping.
+func (c *FakeClock) BlockUntilWaiters(n int) {
+	for {
+		c.m.Lock()
+		if len(c.timers) >= n {
+			c.m.Unlock()
+			return
+		}
+
+		if c.changed == nil {
+			c.changed = make(chan struct{})
+		}
+		ch := c.changed
+		c.m.Unlock()
+
+		<-ch
+	}
+}
+
+// advanceTo fires each timer with a deadline on or before t, then sets the
+// clock's current time to t.
+//
+// c.m must be locked.
+func (c *FakeClock) advanceTo(t time.Time) {
+	sort.Slice(c.timers, func(i, j int) bool {
+		a, b := c.timers[i], c.timers[j]
+
+		if a.deadline.Equal(b.deadline) {
+			return a.seq < b.seq
+		}
+
+		return a.deadline.Before(b.deadline)
+	})
+
+	i := 0
+	for _, timer := range c.timers {
+		if timer.deadline.After(t) {
+			break
+		}
+
+		c.now = timer.deadline
+		timer.ch <- timer.deadline
+		i++
+	}
+
+	if i > 0 {
+		c.timers = c.timers[i:]
+		c.notify()
+	}
+
+	c.now = t
+}
+
+// remove removes t from the set of pending timers.
+//
+// It returns false if t was not pending.
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for i, x := range c.timers {
+		if x == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.notify()
+			return true
+		}
+	}
+
+	return false
+}
+
+// notify wakes any goroutines blocked in BlockUntilWaiters().
+//
+// c.m must be locked.
+func (c *FakeClock) notify() {
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+// fakeTimer is an implementation of linger.Timer that is fired by a
+// FakeClock.
+type fakeTimer struct {
+	clock    *FakeClock
+	seq      uint64
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	return t.clock.remove(t)
+}
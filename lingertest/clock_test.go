@@ -0,0 +1,178 @@
+package lingertest_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/dogmatiq/linger"
+	. "github.com/dogmatiq/linger/lingertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("type FakeClock", func() {
+	var (
+		epoch time.Time
+		clock *FakeClock
+	)
+
+	BeforeEach(func() {
+		epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = NewFakeClock(epoch)
+	})
+
+	Describe("func Now()", func() {
+		It("returns the initial time", func() {
+			Expect(clock.Now()).To(Equal(epoch))
+		})
+
+		It("does not advance on its own", func() {
+			time.Sleep(1 * time.Millisecond)
+			Expect(clock.Now()).To(Equal(epoch))
+		})
+	})
+
+	Describe("func Until()", func() {
+		It("returns the duration until the given time", func() {
+			Expect(clock.Until(epoch.Add(10 * time.Second))).To(Equal(10 * time.Second))
+		})
+	})
+
+	Describe("func Advance()", func() {
+		It("moves the current time forward", func() {
+			clock.Advance(10 * time.Second)
+			Expect(clock.Now()).To(Equal(epoch.Add(10 * time.Second)))
+		})
+
+		It("fires timers that become due", func() {
+			t := clock.NewTimer(10 * time.Second)
+
+			clock.Advance(9 * time.Second)
+			Consistently(t.C()).ShouldNot(Receive())
+
+			clock.Advance(1 * time.Second)
+			Eventually(t.C()).Should(Receive(Equal(epoch.Add(10 * time.Second))))
+		})
+
+		It("fires each timer at its own deadline", func() {
+			t1 := clock.NewTimer(20 * time.Second)
+			t2 := clock.NewTimer(10 * time.Second)
+			t3 := clock.NewTimer(30 * time.Second)
+
+			clock.Advance(1 * time.Minute)
+
+			Expect(t1.C()).To(Receive(Equal(epoch.Add(20 * time.Second))))
+			Expect(t2.C()).To(Receive(Equal(epoch.Add(10 * time.Second))))
+			Expect(t3.C()).To(Receive(Equal(epoch.Add(30 * time.Second))))
+			Expect(clock.Now()).To(Equal(epoch.Add(1 * time.Minute)))
+		})
+
+		It("fires timers in deadline order when advancing past several deadlines", func() {
+			// The timers are registered out of deadline order, then the clock
+			// is advanced past all but the last deadline in a single step.
+			t1 := clock.NewTimer(20 * time.Second)
+			t2 := clock.NewTimer(40 * time.Second)
+			t3 := clock.NewTimer(10 * time.Second)
+			t4 := clock.NewTimer(30 * time.Second)
+
+			clock.Advance(35 * time.Second)
+
+			Expect(t3.C()).To(Receive(Equal(epoch.Add(10 * time.Second))))
+			Expect(t1.C()).To(Receive(Equal(epoch.Add(20 * time.Second))))
+			Expect(t4.C()).To(Receive(Equal(epoch.Add(30 * time.Second))))
+			Expect(t2.C()).NotTo(Receive())
+			Expect(clock.Waiters()).To(Equal(1))
+		})
+
+		It("wakes sleepers whose deadlines are reached by a single advance", func() {
+			ctx := clock.WithContext(context.Background())
+			woken := make(chan time.Duration, 3)
+
+			// The sleepers are registered out of deadline order.
+			for i, d := range []time.Duration{20 * time.Second, 40 * time.Second, 10 * time.Second} {
+				go func() {
+					linger.Sleep(ctx, d)
+					woken <- d
+				}()
+				clock.BlockUntilWaiters(i + 1)
+			}
+
+			clock.Advance(35 * time.Second)
+
+			var order []time.Duration
+			order = append(order, <-woken, <-woken)
+
+			Expect(order).To(ConsistOf(10*time.Second, 20*time.Second))
+			Consistently(woken).ShouldNot(Receive())
+		})
+
+		It("panics if the duration is negative", func() {
+			Expect(func() {
+				clock.Advance(-1)
+			}).To(Panic())
+		})
+	})
+
+	Describe("func Set()", func() {
+		It("sets the current time", func() {
+			t := epoch.Add(1 * time.Hour)
+			clock.Set(t)
+			Expect(clock.Now()).To(Equal(t))
+		})
+
+		It("fires timers that become due", func() {
+			t := clock.NewTimer(10 * time.Second)
+			clock.Set(epoch.Add(1 * time.Hour))
+			Eventually(t.C()).Should(Receive())
+		})
+
+		It("panics if the time is in the past", func() {
+			Expect(func() {
+				clock.Set(epoch.Add(-1))
+			}).To(Panic())
+		})
+	})
+
+	Describe("func NewTimer()", func() {
+		It("fires immediately if the duration is not positive", func() {
+			t := clock.NewTimer(0)
+			Expect(t.C()).To(Receive(Equal(epoch)))
+			Expect(clock.Waiters()).To(Equal(0))
+		})
+
+		It("returns a timer that can be stopped", func() {
+			t := clock.NewTimer(10 * time.Second)
+			Expect(clock.Waiters()).To(Equal(1))
+
+			Expect(t.Stop()).To(BeTrue())
+			Expect(t.Stop()).To(BeFalse())
+			Expect(clock.Waiters()).To(Equal(0))
+
+			clock.Advance(1 * time.Minute)
+			Consistently(t.C()).ShouldNot(Receive())
+		})
+	})
+
+	Describe("func BlockUntilWaiters()", func() {
+		It("blocks until the code under test is sleeping", func() {
+			result := make(chan error, 1)
+
+			go func() {
+				result <- linger.Sleep(
+					clock.WithContext(context.Background()),
+					10*time.Second,
+				)
+			}()
+
+			clock.BlockUntilWaiters(1)
+			clock.Advance(10 * time.Second)
+
+			Eventually(result).Should(Receive(BeNil()))
+		})
+
+		It("returns immediately if there are already enough waiters", func() {
+			clock.NewTimer(10 * time.Second)
+			clock.BlockUntilWaiters(1)
+		})
+	})
+})
@@ -0,0 +1,2 @@
+// Package lingertest provides utilities for testing code that uses linger.
+package lingertest
@@ -20,6 +20,27 @@ func Multiply(d time.Duration, v float64) time.Duration {
 	return FromSeconds(d.Seconds() * v)
 }
 
+// multiplySaturating returns the result of multiplying d by v, saturating at
+// MinDuration and MaxDuration instead of overflowing.
+func multiplySaturating(d time.Duration, v float64) time.Duration {
+	return fromNanosSaturating(float64(d) * v)
+}
+
+// fromNanosSaturating returns the duration equivalent to the given number of
+// nanoseconds, saturating at MinDuration and MaxDuration instead of
+// overflowing.
+func fromNanosSaturating(nanos float64) time.Duration {
+	if nanos >= float64(MaxDuration) {
+		return MaxDuration
+	}
+
+	if nanos <= float64(MinDuration) {
+		return MinDuration
+	}
+
+	return time.Duration(nanos)
+}
+
 // Multiplier returns a DurationTransform that multiplies the input duration by v.
 func Multiplier(v float64) DurationTransform {
 	return func(d time.Duration) time.Duration {
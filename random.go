@@ -0,0 +1,75 @@
+package linger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	mathrand "math/rand/v2"
+	"sync"
+)
+
+// RandSource is a source of uniformly-distributed pseudo-random numbers.
+//
+// It is used by the jitter transforms to produce random durations. It is
+// compatible with the math/rand/v2 Source interface.
+type RandSource interface {
+	// Uint64 returns a pseudo-random 64-bit value.
+	Uint64() uint64
+}
+
+// GlobalRandSource is a RandSource that uses the top-level functions of the
+// math/rand/v2 package.
+//
+// It is safe for concurrent use.
+var GlobalRandSource RandSource = globalRandSource{}
+
+// CryptoRandSource is a RandSource that uses the cryptographically secure
+// random number generator provided by the crypto/rand package.
+//
+// It is safe for concurrent use.
+var CryptoRandSource RandSource = cryptoRandSource{}
+
+// NewSeededRandSource returns a deterministic RandSource that is seeded with
+// the given value.
+//
+// Sources created with the same seed produce the same sequence of values. The
+// returned source is safe for concurrent use.
+func NewSeededRandSource(seed uint64) RandSource {
+	return &lockedRandSource{
+		src: mathrand.NewPCG(seed, seed),
+	}
+}
+
+// globalRandSource is an implementation of RandSource that uses the top-level
+// functions of the math/rand/v2 package.
+type globalRandSource struct{}
+
+func (globalRandSource) Uint64() uint64 {
+	return mathrand.Uint64()
+}
+
+// cryptoRandSource is an implementation of RandSource that uses the
+// crypto/rand package.
+type cryptoRandSource struct{}
+
+func (cryptoRandSource) Uint64() uint64 {
+	var b [8]byte
+
+	// As of Go 1.24, rand.Read() never returns an error.
+	rand.Read(b[:])
+
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// lockedRandSource is an implementation of RandSource that serializes access
+// to another source.
+type lockedRandSource struct {
+	m   sync.Mutex
+	src mathrand.Source
+}
+
+func (s *lockedRandSource) Uint64() uint64 {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.src.Uint64()
+}
@@ -0,0 +1,31 @@
+package linger_test
+
+import (
+	. "github.com/dogmatiq/linger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("func NewSeededRandSource()", func() {
+	It("produces the same sequence for the same seed", func() {
+		a := NewSeededRandSource(123)
+		b := NewSeededRandSource(123)
+
+		for i := 0; i < 10; i++ {
+			Expect(a.Uint64()).To(Equal(b.Uint64()))
+		}
+	})
+
+	It("produces different sequences for different seeds", func() {
+		a := NewSeededRandSource(123)
+		b := NewSeededRandSource(456)
+
+		Expect(a.Uint64()).NotTo(Equal(b.Uint64()))
+	})
+})
+
+var _ = Describe("var CryptoRandSource", func() {
+	It("produces random values", func() {
+		Expect(CryptoRandSource.Uint64()).NotTo(Equal(CryptoRandSource.Uint64()))
+	})
+})
@@ -35,9 +35,32 @@ func SleepX(
 	ctx context.Context,
 	x DurationTransform,
 	durations ...time.Duration,
+) error {
+	return SleepXC(ctx, nil, x, durations...)
+}
+
+// SleepC pauses the current goroutine until some duration has elapsed, as
+// measured by the clock c.
+//
+// It behaves like Sleep(), except that it uses c to measure time. If c is nil,
+// the clock carried by ctx is used, as per ClockFromContext().
+func SleepC(ctx context.Context, c Clock, durations ...time.Duration) error {
+	return SleepXC(ctx, c, Identity, durations...)
+}
+
+// SleepXC pauses the current goroutine until some duration has elapsed, as
+// measured by the clock c.
+//
+// It behaves like SleepX(), except that it uses c to measure time. If c is nil,
+// the clock carried by ctx is used, as per ClockFromContext().
+func SleepXC(
+	ctx context.Context,
+	c Clock,
+	x DurationTransform,
+	durations ...time.Duration,
 ) error {
 	d, _ := Coalesce(durations...)
-	return sleep(ctx, x, d)
+	return sleep(ctx, c, x, d)
 }
 
 // SleepUntil pauses the current goroutine until a specific time.
@@ -71,22 +94,51 @@ func SleepUntilX(
 	x DurationTransform,
 	times ...time.Time,
 ) error {
-	d := time.Until(Earliest(times...))
-	return sleep(ctx, x, d)
+	return SleepUntilXC(ctx, nil, x, times...)
 }
 
-func sleep(ctx context.Context, x DurationTransform, d time.Duration) error {
+// SleepUntilC pauses the current goroutine until a specific time, as measured
+// by the clock c.
+//
+// It behaves like SleepUntil(), except that it uses c to measure time. If c is
+// nil, the clock carried by ctx is used, as per ClockFromContext().
+func SleepUntilC(ctx context.Context, c Clock, times ...time.Time) error {
+	return SleepUntilXC(ctx, c, Identity, times...)
+}
+
+// SleepUntilXC pauses the current goroutine until a specific time, as measured
+// by the clock c.
+//
+// It behaves like SleepUntilX(), except that it uses c to measure time. If c is
+// nil, the clock carried by ctx is used, as per ClockFromContext().
+func SleepUntilXC(
+	ctx context.Context,
+	c Clock,
+	x DurationTransform,
+	times ...time.Time,
+) error {
+	c = clockOrDefault(ctx, c)
+	d := c.Until(Earliest(times...))
+	return sleep(ctx, c, x, d)
+}
+
+func sleep(
+	ctx context.Context,
+	c Clock,
+	x DurationTransform,
+	d time.Duration,
+) error {
 	if d <= 0 {
 		return ctx.Err()
 	}
 
-	t := time.NewTimer(x(d))
+	t := clockOrDefault(ctx, c).NewTimer(x(d))
 	defer t.Stop()
 
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-t.C:
+	case <-t.C():
 		return nil
 	}
 }
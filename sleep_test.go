@@ -5,6 +5,7 @@ import (
 	"time"
 
 	. "github.com/dogmatiq/linger"
+	"github.com/dogmatiq/linger/lingertest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -115,3 +116,98 @@ var _ = Describe("func SleepUntilX()", func() {
 		Expect(elapsed).To(BeNumerically("<", 20*time.Millisecond))
 	})
 })
+
+var _ = Describe("func SleepC()", func() {
+	It("uses the given clock", func() {
+		c := wrappedClock{SystemClock}
+
+		start := time.Now()
+		err := SleepC(context.Background(), c, 10*time.Millisecond)
+		stop := time.Now()
+		elapsed := stop.Sub(start)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(elapsed).To(BeNumerically(">=", 10*time.Millisecond))
+	})
+
+	It("uses the system clock if the clock is nil", func() {
+		start := time.Now()
+		err := SleepC(context.Background(), nil, 10*time.Millisecond)
+		stop := time.Now()
+		elapsed := stop.Sub(start)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(elapsed).To(BeNumerically(">=", 10*time.Millisecond))
+	})
+})
+
+var _ = Describe("func SleepUntilC()", func() {
+	It("uses the given clock", func() {
+		c := wrappedClock{SystemClock}
+
+		start := time.Now()
+		err := SleepUntilC(context.Background(), c, start.Add(10*time.Millisecond))
+		stop := time.Now()
+		elapsed := stop.Sub(start)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(elapsed).To(BeNumerically(">=", 10*time.Millisecond))
+	})
+})
+
+var _ = Describe("func Sleep() (with a fake clock)", func() {
+	var clock *lingertest.FakeClock
+
+	BeforeEach(func() {
+		clock = lingertest.NewFakeClock(time.Now())
+	})
+
+	It("sleeps until the clock carried by the context has advanced", func() {
+		ctx := clock.WithContext(context.Background())
+		result := make(chan error, 1)
+
+		go func() {
+			result <- Sleep(ctx, 1*time.Hour)
+		}()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(59 * time.Minute)
+		Consistently(result).ShouldNot(Receive())
+
+		clock.Advance(1 * time.Minute)
+		Eventually(result).Should(Receive(BeNil()))
+	})
+
+	It("prefers an explicit clock over the clock carried by the context", func() {
+		ctx := clock.WithContext(context.Background())
+		explicit := lingertest.NewFakeClock(time.Now())
+		result := make(chan error, 1)
+
+		go func() {
+			result <- SleepC(ctx, explicit, 1*time.Hour)
+		}()
+
+		explicit.BlockUntilWaiters(1)
+		Expect(clock.Waiters()).To(Equal(0))
+
+		explicit.Advance(1 * time.Hour)
+		Eventually(result).Should(Receive(BeNil()))
+	})
+})
+
+var _ = Describe("func SleepUntil() (with a fake clock)", func() {
+	It("sleeps until the clock reaches the earliest time", func() {
+		clock := lingertest.NewFakeClock(time.Now())
+		ctx := clock.WithContext(context.Background())
+		result := make(chan error, 1)
+
+		go func() {
+			now := clock.Now()
+			result <- SleepUntil(ctx, now.Add(2*time.Hour), now.Add(1*time.Hour))
+		}()
+
+		clock.BlockUntilWaiters(1)
+		clock.Advance(1 * time.Hour)
+		Eventually(result).Should(Receive(BeNil()))
+	})
+})